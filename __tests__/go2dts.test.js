@@ -1,18 +1,24 @@
 const go2dts = require("../src/index");
+const loadConfig = require("../src/config");
 const rimraf = require("rimraf");
 const { join } = require("path");
 const { readFileSync, readdirSync } = require("fs");
 
+const inputs = [
+  join(__dirname, "./inputs/client"),
+  join(__dirname, "./inputs/types"),
+  join(__dirname, "./inputs/labsserver/httputils")
+];
+
+const generate = (name, config, dirs = inputs) => {
+  const outFile = join(__dirname, `./outputs/${name}.d.ts`);
+  go2dts(dirs, outFile, config);
+  return readFileSync(outFile, "utf-8");
+};
+
 beforeAll(next => {
   rimraf(join(__dirname, "./outputs"), () => {
-    go2dts(
-      [
-        join(__dirname, "./inputs/client"),
-        join(__dirname, "./inputs/types"),
-        join(__dirname, "./inputs/labsserver/httputils")
-      ],
-      join(__dirname, "./outputs/labs.types.d.ts")
-    );
+    go2dts(inputs, join(__dirname, "./outputs/labs.types.d.ts"));
     next();
   });
 });
@@ -24,3 +30,644 @@ describe("go2dts", () => {
     ).toMatchSnapshot();
   });
 });
+
+describe("go2dts with config", () => {
+  it("should use the user type mapping", () => {
+    const output = generate(
+      "types",
+      loadConfig(join(__dirname, "./configs/types.json"))
+    );
+
+    expect(output).toContain(`import { Json } from "@labs/json"`);
+    expect(output).toContain("  schema: Json\n");
+    expect(output).toContain("  tags: ReadonlyArray<string>\n");
+    expect(output).toContain("  environment: {[key: string]: string}\n");
+  });
+
+  it("should report the invalid type mappings", () => {
+    expect(() =>
+      generate("types-invalid", {
+        types: { "json.RawMessage": { from: "./x" } }
+      })
+    ).toThrow(`Invalid mapping of "json.RawMessage": "type" is required`);
+    expect(() =>
+      generate("types-invalid", {
+        types: { "json.RawMessage": { type: "{}", from: "./x" } }
+      })
+    ).toThrow(`Invalid mapping of "json.RawMessage": "import" is required`);
+  });
+
+  it("should apply the overrides", () => {
+    const log = jest.spyOn(console, "log").mockImplementation(() => {});
+    const output = generate(
+      "overrides",
+      loadConfig(join(__dirname, "./configs/overrides.json")),
+      inputs.concat(join(__dirname, "./inputs/accounts"))
+    );
+    log.mockRestore();
+
+    expect(output).toContain("  config: Partial<Bundle>\n");
+    expect(output).toContain("  nextPage: number\n");
+    expect(output).toContain(
+      "export interface Account {\n  id?: string\n  quota?: number\n}"
+    );
+    expect(output).not.toContain("export type Color");
+    expect(log.mock.calls.join("\n")).toContain(
+      `override "types.UnknownType.field" doesn't match anything`
+    );
+  });
+
+  it("should model the encoding/json nullability in strict mode", () => {
+    const output = generate("strict", { nullability: "strict" });
+
+    expect(output).toContain("  name: string | null\n"); // *string
+    expect(output).toContain("  next?: number\n"); // int,omitempty
+    expect(output).toContain("  build: FunctionBuild\n"); // struct,omitempty
+    expect(output).toContain("  secrets?: string[]\n"); // []string,omitempty
+    expect(output).toContain("  data: BundleResponse[] | null\n"); // []*T
+    expect(output).toContain("  shortcuts?: string[] | null\n"); // *[]string,omitempty
+  });
+
+  it("should split the types by direction", () => {
+    const output = generate("directions", {
+      directions: { naming: true, types: { "types.Bundle": "both" } }
+    });
+
+    expect(output).toMatch(/interface RegisterBundleRequest {\n  name\?: string/);
+    expect(output).toMatch(/interface SettingsUpdateRequest {\n  name: string/);
+    expect(output).toMatch(/interface Settings {\n  name\?: string/);
+    expect(output).toContain("export interface BundleInput {");
+    expect(output).toContain("export interface BundleOutput {");
+    expect(output).toContain("export interface EditConfigInput {");
+    expect(output).toContain("  config: BundleOutput\n");
+    expect(output).not.toContain("export interface Bundle {");
+  });
+
+  it("should use the variants in the methods and the routes", () => {
+    const dirs = [join(__dirname, "./inputs/directions")];
+    const output = generate(
+      "directions-methods",
+      { directions: { naming: true }, interfaces: true, routes: true },
+      dirs
+    );
+
+    expect(output).toContain("  get(id: string): Promise<BundleOutput>\n");
+    expect(output).toContain("  save(bundle: BundleInput): Promise<void>\n");
+    expect(output).toContain(
+      '  "PUT /bundles": { request: BundleInput; response: BundleOutput }\n'
+    );
+    expect(output).not.toMatch(/\bBundle\b/);
+  });
+
+  it("should apply the int64 policy", () => {
+    const dirs = inputs.concat(join(__dirname, "./inputs/accounts"));
+    expect(generate("int64", { int64: "string" }, dirs)).toMatch(
+      /version: Int64\n  revision: Int64\n[^]*quota\?: Int64\n/
+    );
+    const brands = readFileSync(
+      join(__dirname, "./outputs/int64.brands.ts"),
+      "utf-8"
+    );
+    expect(brands).toContain('import { Int64 } from "./int64";');
+    expect(brands).toContain("export const parseInt64 = ");
+
+    const output = generate("bigint", { int64: "bigint" }, dirs);
+    expect(output).toMatch(
+      /version: bigint\n  revision: bigint\n[^]*quota\?: bigint\n/
+    );
+    const reviver = readFileSync(
+      join(__dirname, "./outputs/bigint.reviver.ts"),
+      "utf-8"
+    );
+    expect(reviver).toContain(
+      '  "Settings": {\n    "version": "int64",\n    "revision": "int64"\n  }'
+    );
+    expect(reviver).toContain('"quota": "int64"');
+  });
+
+  it("should follow the types in the bigint reviver", () => {
+    const dirs = [join(__dirname, "./inputs/int64")];
+    generate("int64-shapes", { int64: "bigint" }, dirs);
+    const reviver = readFileSync(
+      join(__dirname, "./outputs/int64-shapes.reviver.ts"),
+      "utf-8"
+    );
+
+    // `Job.id` is an int64, `User.id` an uuid
+    expect(reviver).toContain('export type Int64Type = "Job" | "User";');
+    expect(reviver).toContain(
+      '  "Job": {\n' +
+        '    "id": "int64",\n' +
+        '    "sizes": {\n      "*": "int64"\n    },\n' +
+        '    "owner": "User"\n  },\n' +
+        '  "User": {\n' +
+        '    "parent": "User",\n' +
+        '    "credits": {\n      "*": "int64"\n    }\n  }\n'
+    );
+    expect(reviver).toContain('/^-?\\d+$/.test(value)');
+  });
+
+  it("should emit branded types", () => {
+    const output = generate("branded", { branded: true });
+
+    expect(output).toContain(
+      `export type UUID = string & { readonly __brand: "UUID" }`
+    );
+    expect(output).toContain(
+      `export type TenantID = string & { readonly __brand: "TenantID" }`
+    );
+
+    const helpers = readFileSync(
+      join(__dirname, "./outputs/branded.brands.ts"),
+      "utf-8"
+    );
+    expect(helpers).toContain(
+      `import { Time, UUID, TenantID } from "./branded";`
+    );
+    expect(helpers).toContain("export const isUUID = ");
+    expect(helpers).toContain("export const parseTenantID = ");
+  });
+
+  it("should only declare the referenced scalars", () => {
+    const dirs = [join(__dirname, "./inputs/scalars")];
+    const output = generate(
+      "scalars",
+      { scalars: { Duration: "string" } },
+      dirs
+    );
+
+    expect(output).not.toContain("export type Time");
+    expect(output).not.toContain("export type UUID");
+    expect(output).toContain("export type Duration = string");
+
+    const shared = generate(
+      "shared-scalars",
+      { scalars: { Duration: "string" }, scalarsFrom: "@labs/scalars" },
+      dirs
+    );
+    expect(shared).toContain(`import { Duration } from "@labs/scalars"`);
+    expect(shared).not.toContain("export type Duration");
+  });
+
+  it("should emit the map keys as records", () => {
+    const dirs = [join(__dirname, "./inputs/maps")];
+    const output = generate("maps", {}, dirs);
+
+    expect(output).toContain("  byState: Partial<Record<JobState, number>>\n");
+    expect(output).toContain("  byPriority: Record<string, string[]>\n");
+    expect(output).toContain("  byHour: Record<string, JobState>\n");
+    expect(output).toContain(
+      "  labels: {[key: string]: {[key: string]: number}[]}\n"
+    );
+    expect(output).toContain(
+      "  runners: {[key: string]: Partial<Record<JobState, number>>}[]\n"
+    );
+  });
+
+  it("should emit the fixed-size arrays as tuples", () => {
+    const dirs = [join(__dirname, "./inputs/arrays")];
+    const output = generate("arrays", {}, dirs);
+
+    expect(output).toContain("  content: string\n");
+    expect(output).toContain("  digest: number[]\n");
+    expect(output).toContain("  position: [number, number, number]\n");
+    expect(output).toContain(
+      "  corners: [[number, number], [number, number], [number, number], [number, number]]\n"
+    );
+    expect(output).toContain("  chunks: string[]\n");
+    expect(output).toContain("  tags: [string[], string[]]\n");
+
+    const short = generate("short-tuples", { maxTupleLength: 2 }, dirs);
+    expect(short).toContain("  position: number[]\n");
+    expect(short).toContain("  corners: [number, number][]\n");
+  });
+
+  it("should emit the nullable pointer elements", () => {
+    const dirs = [join(__dirname, "./inputs/pointers")];
+    const output = generate("pointers", {}, dirs);
+
+    expect(output).toContain("  children?: Node[]\n");
+    expect(output).toContain("  siblings?: Node[]\n");
+    expect(output).toContain("  levels?: string[][]\n");
+
+    const nullable = generate(
+      "nullable-pointers",
+      { nullableElements: true },
+      dirs
+    );
+    expect(nullable).toContain("  children: (Node | null)[]\n");
+    expect(nullable).toContain("  siblings?: Node[]\n");
+    expect(nullable).toContain("  levels: (string[] | null)[]\n");
+    expect(nullable).toContain("  index: {[key: string]: Node | null}\n");
+    expect(nullable).toContain(
+      "  grid: [(number | null)[], (number | null)[]]\n"
+    );
+    expect(nullable).toContain("  paths: Record<string, (string | null)[]>\n");
+  });
+
+  it("should name the declarations and their references", () => {
+    const dirs = [join(__dirname, "./inputs/naming")];
+    const output = generate("naming", {}, dirs);
+
+    expect(output).toContain("export interface HTTPError {");
+    expect(output).toContain("export interface JsonErrorMessage {");
+    expect(output).toContain("  message: JsonErrorMessage\n");
+    expect(output).toContain("  details: FieldError[]\n");
+
+    const pascal = generate(
+      "naming-pascal",
+      { naming: { case: "pascal" } },
+      dirs
+    );
+    expect(pascal).toContain("export interface HttpError {");
+
+    const affixed = generate(
+      "naming-affixed",
+      { naming: { case: "preserve", prefix: "I", suffix: "Dto" } },
+      dirs
+    );
+    expect(affixed).toContain("export interface IHTTPErrorDto {");
+    expect(affixed).toContain("  message: IjsonErrorMessageDto\n");
+    expect(affixed).toContain("  details: FieldError[]\n");
+  });
+
+  it("should rename the colliding declarations", () => {
+    const dirs = ["auth", "billing"].map(dir =>
+      join(__dirname, "./inputs/collisions", dir)
+    );
+    const log = jest.spyOn(console, "log").mockImplementation(() => {});
+    const output = generate("collisions", {}, dirs);
+    const report = log.mock.calls.join("\n");
+    log.mockRestore();
+
+    expect(output).toContain("export interface AuthConfig {");
+    expect(output).toContain("export interface BillingConfig {");
+    expect(output).toContain("export interface AuthRecord {");
+    expect(output).toContain("  auth: AuthConfig\n");
+    expect(output).toContain("  config: BillingConfig\n");
+    expect(output).toContain("  history: AuthRecord[]\n");
+    expect(output).toContain("  totals: {[key: string]: AuthRecord}\n");
+    expect(report).toContain(
+      `"auth.Config" renamed to "AuthConfig" (declared in several packages)`
+    );
+    expect(report).toContain(
+      `"auth.Record" renamed to "AuthRecord" (shadows the typescript "Record")`
+    );
+
+    const renamed = generate(
+      "collisions-renamed",
+      {
+        overrides: {
+          "auth.Config": { name: "AuthSettings" },
+          "auth.Record": { name: "AuditRecord" }
+        }
+      },
+      dirs
+    );
+    expect(renamed).toContain("export interface AuthSettings {");
+    expect(renamed).toContain("export interface Config {");
+    expect(renamed).toContain("  history: AuditRecord[]\n");
+  });
+
+  it("should apply the directives of the types of their package", () => {
+    const dirs = ["auth", "billing"].map(dir =>
+      join(__dirname, "./inputs/directives", dir)
+    );
+    const output = generate("directives", {}, dirs);
+    expect(output).not.toContain("Issuer");
+    expect(output).toContain("export interface Config {\n  currency: string");
+    expect(output).toContain("  mode: BillingMode\n");
+    expect(output).toContain("export interface BillingMode {");
+    expect(output).toContain('export type Mode = "token" | "oauth"');
+  });
+
+  it("should apply the unexported types policy", () => {
+    const dirs = [join(__dirname, "./inputs/unexported")];
+    const included = generate("unexported", {}, dirs);
+    expect(included).toContain("export interface CacheItem {");
+
+    const skipped = generate("unexported-skip", { unexported: "skip" }, dirs);
+    expect(skipped).not.toContain("export interface Entry {");
+    expect(skipped).not.toContain("export interface ErrorPayload {");
+    expect(skipped).toContain("  entries: unknown[]\n");
+    expect(skipped).toContain("export interface Options {");
+
+    const referenced = generate(
+      "unexported-referenced",
+      { unexported: "referenced" },
+      dirs
+    );
+    expect(referenced).toContain("  entries: Entry[]\n");
+    expect(referenced).toContain("export interface Entry {");
+    expect(referenced).toContain("export interface ErrorPayload {");
+    expect(referenced).toContain("export interface Options {");
+    expect(referenced).not.toContain("export interface CacheItem {");
+  });
+
+  it("should map the protobuf well-known types", () => {
+    const pb = join(__dirname, "./inputs/protobuf");
+    const json = generate("protobuf", {}, [pb]);
+    expect(json).toContain(
+      "  createdAt?: {seconds?: number, nanos?: number}\n"
+    );
+    expect(json).toContain("  label?: {value?: string}\n");
+    expect(json).toContain("  payload?: {type_url?: string, value?: string}\n");
+    expect(json).toContain("  mask?: {paths?: string[]}\n");
+    expect(json).toContain("  size?: number\n");
+
+    const dirs = [pb, join(__dirname, "./inputs/protojson")];
+    const protojson = generate("protojson", {}, dirs);
+    expect(protojson).toContain("  createdAt?: Time\n");
+    expect(protojson).toContain("  timeout?: string\n");
+    expect(protojson).toContain("  label?: string | null\n");
+    expect(protojson).toContain("  retries?: string | null\n");
+    expect(protojson).toContain("  attributes?: {[key: string]: unknown}\n");
+    expect(protojson).toContain(
+      `  payload?: {"@type": string, [key: string]: unknown}\n`
+    );
+    expect(protojson).toContain("  mask?: string\n");
+    expect(protojson).toContain("  ack?: {}\n");
+    expect(protojson).toContain("  size?: string\n");
+
+    const forced = generate("protobuf-forced", { protobuf: "protojson" }, [pb]);
+    expect(forced).toContain("  createdAt?: Time\n");
+  });
+
+  it("should emit the protobuf oneofs as discriminated unions", () => {
+    const dirs = [join(__dirname, "./inputs/protobuf")];
+    const json = generate("oneofs-json", {}, dirs);
+    expect(json).toContain(`export interface Command {
+  id?: string
+  Action?: {Start?: StartAction} | {Stop?: StopAction}
+  Target?: {BundleId: string} | {JobName: string}
+}`);
+
+    const output = generate("oneofs", { protobuf: "protojson" }, dirs);
+    expect(output).toContain(`export type Command = {
+  id?: string
+} & (
+  | { start: StartAction; stop?: never }
+  | { stop: StopAction; start?: never }
+  | { start?: never; stop?: never }
+) & (
+  | { bundleId: string; jobName?: never }
+  | { jobName: string; bundleId?: never }
+  | { bundleId?: never; jobName?: never }
+)`);
+    expect(output).not.toContain("Command_BundleId");
+  });
+
+  it("should emit the protobuf enums", () => {
+    const dirs = [join(__dirname, "./inputs/protobuf")];
+    const json = generate("proto-enums-json", {}, dirs);
+    expect(json).toContain("export type JobStatus = 0 | 1 | 2");
+    expect(json).toContain("export type Priority = never");
+
+    const output = generate(
+      "proto-enums",
+      { enumObjects: true, protobuf: "protojson" },
+      dirs
+    );
+    expect(output).toContain(
+      `export type JobStatus = "UNKNOWN" | "RUNNING" | "DONE"`
+    );
+    expect(output).toContain("  status?: JobStatus\n");
+
+    const objects = readFileSync(
+      join(__dirname, "./outputs/proto-enums.enums.ts"),
+      "utf-8"
+    );
+    expect(objects).toContain(`export const JobStatus = {
+  UNKNOWN: "UNKNOWN",
+  RUNNING: "RUNNING",
+  DONE: "DONE"
+} as const;`);
+
+    const ints = generate("proto-enums-ints", { enumsAsInts: true }, dirs);
+    expect(ints).toContain("export type JobStatus = 0 | 1 | 2");
+  });
+
+  it("should name the protobuf fields like their serializer", () => {
+    const dirs = [join(__dirname, "./inputs/protobuf")];
+    const json = generate("proto-json-names", {}, dirs);
+    expect(json).toContain("  display_name?: string\n");
+    expect(json).toContain("  tenant_id: string\n");
+    expect(json).not.toContain("XXX_sizecache");
+
+    const protojson = generate(
+      "proto-protojson-names",
+      { protobuf: { events: "protojson" } },
+      dirs
+    );
+    expect(protojson).toContain("  displayName?: string\n");
+    expect(protojson).toContain("  tenantId?: string\n");
+    expect(protojson).not.toContain("XXX_sizecache");
+  });
+
+  it("should read the proto files", () => {
+    const dirs = ["proto", "maps"].map(dir => join(__dirname, "./inputs", dir));
+    const output = generate("proto", {}, dirs);
+
+    expect(output).toContain("export interface JobStats {");
+    expect(output).toMatchSnapshot();
+  });
+
+  it("should emit the grpc services", () => {
+    const dirs = [join(__dirname, "./inputs/grpc")];
+    const output = generate("grpc", {}, dirs);
+
+    expect(output).toContain("export interface UserService {\n");
+    expect(output).toContain(
+      "  /** `GET /v1/users/{id}` */\n" +
+        "  getUser(req: GetUserRequest): Promise<User>\n"
+    );
+    expect(output).toContain(
+      "  listUsers(req: ListUsersRequest): AsyncIterable<User>\n"
+    );
+    expect(output).toContain(
+      "  uploadAvatar(req: AsyncIterable<Chunk>): Promise<Avatar>\n"
+    );
+    expect(output).toContain(
+      "  chat(req: AsyncIterable<Message>): AsyncIterable<Message>\n"
+    );
+    expect(output).not.toContain("UserService_ListUsersServer");
+    expect(output).not.toContain("export interface API {");
+
+    const interfaces = generate("grpc-interfaces", { interfaces: true }, dirs);
+    expect(interfaces).toContain("export interface APIServer {\n");
+    expect(interfaces).toContain("  shutdown(): Promise<void>\n");
+  });
+
+  it("should emit the go interfaces", () => {
+    const dirs = [join(__dirname, "./inputs/client")];
+    expect(generate("client-no-interfaces", {}, dirs)).not.toContain(
+      "export interface BundleClient {"
+    );
+
+    const log = jest.spyOn(console, "log").mockImplementation(() => {});
+    const output = generate("interfaces", { interfaces: true }, dirs);
+    const report = log.mock.calls.join("\n");
+    log.mockRestore();
+    expect(output).toContain("export interface BundleClient {\n");
+    expect(output).toContain(
+      "  register(req: RegisterBundleRequest): Promise<BundleResponse>\n"
+    );
+    expect(output).toContain("  unregister(id: UUID): Promise<void>\n");
+    expect(output).toContain("  startEditSession(id: UUID): Promise<string>\n");
+    expect(output).toContain("  getTenantID(): string\n");
+    expect(output).toContain("  setRealm(arg0: string): void\n");
+    expect(output).toContain(
+      "export interface HTTPClient {\n  do(req: unknown): Promise<unknown>\n}"
+    );
+    expect(report).toContain(
+      `type "http.Request" not found, typed as unknown`
+    );
+  });
+
+  it("should emit the fetch clients", () => {
+    const dirs = [join(__dirname, "./inputs/client")];
+    const output = generate("client", { client: true }, dirs);
+    expect(output).not.toContain("BundleClientImpl");
+
+    const client = readFileSync(
+      join(__dirname, "./outputs/client.client.ts"),
+      "utf-8"
+    );
+    expect(client).toContain("export class BundleClientImpl {");
+    expect(client).toContain(
+      "  async register(req: RegisterBundleRequest): Promise<BundleResponse> {\n" +
+        "    const response = await this.fetch(this.url(), {\n" +
+        '      method: "POST",\n' +
+        '      headers: { "Content-Type": "application/json" },\n' +
+        "      body: JSON.stringify(req)\n"
+    );
+    expect(client).toContain('this.url(String(id), "sync")');
+    expect(client).toContain("return (await response.json()).url;");
+    expect(client).toContain(
+      "  async stopEditSession(id: UUID, deleteVolumes: boolean): Promise<void> {"
+    );
+    expect(client).toMatchSnapshot();
+  });
+
+  it("should emit the routes", () => {
+    const dirs = inputs.concat(
+      join(__dirname, "./inputs/labsserver/server"),
+      join(__dirname, "./inputs/labsserver/version")
+    );
+    expect(generate("no-routes", {}, dirs)).not.toContain("Routes");
+
+    const output = generate("routes", { routes: true }, dirs);
+    expect(output).toContain("export interface Routes {\n");
+    expect(output).toContain(
+      '  "/healthz": { request: void; response: HealthResponse }\n'
+    );
+    expect(output).toContain(
+      '  "POST /api/v1/bundles": ' +
+        "{ request: RegisterBundleRequest; response: BundleResponse }\n"
+    );
+    expect(output).toContain(
+      '  "HEAD /api/v1/bundles/{id}": { request: void; response: BundleResponse }'
+    );
+    expect(output).toContain(
+      '  "POST /api/v1/deployments": ' +
+        "{ request: DeployRequest; response: DeployResponse }\n"
+    );
+    expect(output).toContain(
+      '  "GET /api/v1/deployments/{id}": ' +
+        "{ request: void; response: DeployResponse[] }\n"
+    );
+    expect(output).toContain(
+      '  "DELETE /api/v1/deployments/{id}": { request: void; response: void }\n'
+    );
+    expect(output).toContain(
+      '  "/version": { request: void; response: Response }\n'
+    );
+    expect(output).toContain(
+      '  "GET /things": { request: unknown; response: unknown }\n'
+    );
+  });
+
+  it("should emit the api error envelope", () => {
+    expect(generate("no-api-error", {})).toContain(
+      "export interface JsonErrorMessage {"
+    );
+
+    const output = generate("api-error", {
+      client: true,
+      errorWriters: ["JSONError"]
+    });
+    expect(output).toContain(
+      "export interface ApiError<T = {[key: string]: unknown}> {\n" +
+        "  errors: string[]\n" +
+        "  fieldErrors: Partial<Record<keyof T & string, string[]>>\n}"
+    );
+    expect(output).not.toContain("JsonErrorMessage");
+
+    const errors = readFileSync(
+      join(__dirname, "./outputs/api-error.errors.ts"),
+      "utf-8"
+    );
+    expect(errors).toContain('import { ApiError } from "./api-error";');
+    expect(errors).toContain(
+      "  Array.isArray((value as ApiError).errors) &&\n" +
+        '  typeof (value as ApiError).fieldErrors === "object";'
+    );
+    expect(errors).toMatchSnapshot();
+
+    const client = readFileSync(
+      join(__dirname, "./outputs/api-error.client.ts"),
+      "utf-8"
+    );
+    expect(client).toContain(
+      'import { parseApiError } from "./api-error.errors";'
+    );
+    expect(client).toContain(
+      "throw await unexpectedStatus<RegisterBundleRequest>(response);"
+    );
+
+    generate("api-error-renamed", {
+      client: true,
+      errorWriters: ["JSONError"],
+      overrides: { "httputils.jsonErrorMessage": { name: "HTTPError" } }
+    });
+    const renamed = readFileSync(
+      join(__dirname, "./outputs/api-error-renamed.errors.ts"),
+      "utf-8"
+    );
+    expect(renamed).toContain(
+      'import { HTTPError } from "./api-error-renamed";'
+    );
+    expect(renamed).toContain("): value is HTTPError<T> =>");
+    const renamedClient = readFileSync(
+      join(__dirname, "./outputs/api-error-renamed.client.ts"),
+      "utf-8"
+    );
+    expect(renamedClient).toContain("  HTTPError\n} from");
+    expect(renamedClient).toContain("    readonly apiError?: HTTPError<T>\n");
+  });
+
+  it("should emit the date decoders", () => {
+    const dirs = [join(__dirname, "./inputs/decoders")];
+    const output = generate("decoders", { decoders: true }, dirs);
+
+    expect(output).toContain(
+      "export interface DecodedScheduleHistory extends DecodedSchedule {"
+    );
+    expect(output).toContain("  runs: {[key: string]: Date[]}\n");
+    expect(output).toContain("  executions: DecodedExecution[]\n");
+    expect(output).toContain("  completedAt: Date | null\n");
+    expect(output).toContain("  window: [Date, Date]\n");
+
+    const decoders = readFileSync(
+      join(__dirname, "./outputs/decoders.decoders.ts"),
+      "utf-8"
+    );
+    expect(decoders).toContain(
+      "    executions: json.executions == null ? json.executions : json.executions.map(i0 => decodeExecution(i0)),\n"
+    );
+    expect(decoders).toContain(
+      "    deletedAt: json.deletedAt == null ? json.deletedAt : mapValues(json.deletedAt, i0 => new Date(i0))\n"
+    );
+    expect(decoders).toMatchSnapshot();
+  });
+});
@@ -0,0 +1,13 @@
+package arrays
+
+// Artifact is a file produced by a job
+type Artifact struct {
+	Name     string      `json:"name"`
+	Content  []byte      `json:"content"`
+	Checksum [16]byte    `json:"checksum"`
+	Digest   [32]uint8   `json:"digest"`
+	Position [3]float64  `json:"position"`
+	Corners  [4][2]int   `json:"corners"`
+	Chunks   [][]byte    `json:"chunks"`
+	Tags     [2][]string `json:"tags"`
+}
@@ -0,0 +1,12 @@
+package auth
+
+// Config of the authentication
+type Config struct {
+	Issuer string `json:"issuer"`
+}
+
+// Record is an audit log entry
+type Record struct {
+	Action string `json:"action"`
+	Config Config `json:"config"`
+}
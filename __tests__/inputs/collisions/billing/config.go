@@ -0,0 +1,16 @@
+package billing
+
+import "github.com/contiamo/labs/pkg/auth"
+
+// Config of the billing
+type Config struct {
+	Currency string      `json:"currency"`
+	Auth     auth.Config `json:"auth"`
+}
+
+// Invoice is sent every month
+type Invoice struct {
+	Config  Config                 `json:"config"`
+	History []auth.Record          `json:"history"`
+	Totals  map[string]auth.Record `json:"totals"`
+}
@@ -0,0 +1,34 @@
+package decoders
+
+import (
+	"time"
+
+	"github.com/contiamo/labs/pkg/sql/null"
+)
+
+// Execution describes a job execution
+type Execution struct {
+	StartedAt   time.Time `json:"startedAt"`
+	CompletedAt null.Time `json:"completedAt"`
+}
+
+// Schedule describes when a job is executed
+type Schedule struct {
+	Cron      string       `json:"cron"`
+	NextRunAt *time.Time   `json:"nextRunAt"`
+	Window    [2]time.Time `json:"window"`
+}
+
+// ScheduleHistory contains the executions of a schedule
+type ScheduleHistory struct {
+	Schedule
+	Runs       map[string][]time.Time `json:"runs"`
+	Executions []Execution            `json:"executions"`
+}
+
+// Archive contains the executions of the deleted schedules (go encodes the nil
+// slices and maps as `null`)
+type Archive struct {
+	Executions []Execution          `json:"executions"`
+	DeletedAt  map[string]time.Time `json:"deletedAt"`
+}
@@ -0,0 +1,48 @@
+package bundles
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// Bundle is sent and received by the bundles API
+type Bundle struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// CreateBundleRequest is the payload to create a bundle
+type CreateBundleRequest struct {
+	Bundle Bundle `json:"bundle"`
+}
+
+// BundleResponse describes a bundle
+type BundleResponse struct {
+	Bundle Bundle `json:"bundle"`
+}
+
+// BundleStore persists the bundles
+type BundleStore interface {
+	Get(ctx context.Context, id string) (*Bundle, error)
+	Save(ctx context.Context, bundle *Bundle) error
+}
+
+// Handler serves the bundles API
+type Handler struct {
+	store BundleStore
+}
+
+// NewRouter registers the bundles API
+func (h *Handler) NewRouter() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /bundles", h.save)
+	return mux
+}
+
+func (h *Handler) save(w http.ResponseWriter, r *http.Request) {
+	var bundle Bundle
+	json.NewDecoder(r.Body).Decode(&bundle)
+	h.store.Save(r.Context(), &bundle)
+	json.NewEncoder(w).Encode(bundle)
+}
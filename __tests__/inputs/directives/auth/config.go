@@ -0,0 +1,15 @@
+package auth
+
+// Config of the authentication, only used by the server
+//go2dts:ignore
+type Config struct {
+	Issuer string `json:"issuer"`
+}
+
+// Mode of the authentication
+type Mode string
+
+const (
+	ModeToken Mode = "token"
+	ModeOAuth Mode = "oauth"
+)
@@ -0,0 +1,13 @@
+package billing
+
+// Config of the billing
+type Config struct {
+	Currency string `json:"currency"`
+	Mode     Mode   `json:"mode"`
+}
+
+// Mode of the billing
+//go2dts:name BillingMode
+type Mode struct {
+	Prepaid bool `json:"prepaid"`
+}
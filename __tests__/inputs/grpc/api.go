@@ -0,0 +1,13 @@
+package users
+
+import (
+	"context"
+	"net"
+)
+
+// APIServer serves the users API (hand-written, not a gRPC server)
+type APIServer interface {
+	ListenAndServe() error
+	Serve(l net.Listener) error
+	Shutdown(ctx context.Context) error
+}
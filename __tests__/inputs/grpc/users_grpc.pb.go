@@ -0,0 +1,40 @@
+package users
+
+import (
+	context "context"
+
+	grpc "google.golang.org/grpc"
+)
+
+// UserServiceClient is the client API for UserService service.
+type UserServiceClient interface {
+	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error)
+	ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (UserService_ListUsersClient, error)
+	UploadAvatar(ctx context.Context, opts ...grpc.CallOption) (UserService_UploadAvatarClient, error)
+	Chat(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Message, Message], error)
+}
+
+// UserServiceServer is the server API for UserService service.
+// All implementations must embed UnimplementedUserServiceServer
+// for forward compatibility
+type UserServiceServer interface {
+	GetUser(context.Context, *GetUserRequest) (*User, error)
+	ListUsers(*ListUsersRequest, UserService_ListUsersServer) error
+	UploadAvatar(UserService_UploadAvatarServer) error
+	Chat(UserService_ChatServer) error
+	mustEmbedUnimplementedUserServiceServer()
+}
+
+type UserService_ListUsersServer interface {
+	Send(*User) error
+	grpc.ServerStream
+}
+
+type UserService_UploadAvatarServer interface {
+	SendAndClose(*Avatar) error
+	Recv() (*Chunk, error)
+	grpc.ServerStream
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type UserService_ChatServer = grpc.BidiStreamingServer[Message, Message]
@@ -0,0 +1,26 @@
+package ids
+
+import (
+	uuid "github.com/satori/go.uuid"
+)
+
+// Job is identified by a sequence number
+type Job struct {
+	ID    int64            `json:"id"`
+	Sizes map[string]int64 `json:"sizes"`
+	Owner *User            `json:"owner"`
+}
+
+// User is identified by an uuid
+type User struct {
+	ID      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	Parent  *User     `json:"parent"`
+	Credits []int64   `json:"credits"`
+}
+
+// Team shares no 64-bit integer
+type Team struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
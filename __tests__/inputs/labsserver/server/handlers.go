@@ -0,0 +1,72 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/contiamo/labs/pkg/client"
+	"github.com/contiamo/labs/pkg/labsserver/httputils"
+)
+
+// Handler serves the bundles API
+type Handler struct {
+	store Store
+}
+
+type healthResponse struct {
+	Status string `json:"status"`
+}
+
+type deployRequest struct {
+	BundleID string `json:"bundleId"`
+	Force    bool   `json:"force"`
+}
+
+func (h *Handler) health(w http.ResponseWriter, r *http.Request) {
+	httputils.JSONResponse(w, healthResponse{Status: "ok"})
+}
+
+func (h *Handler) listBundles(w http.ResponseWriter, r *http.Request) {
+	bundles, err := h.store.List(r.Context())
+	if err != nil {
+		httputils.JSONServerError(w, err)
+		return
+	}
+	response := client.BundleListResponse{Data: bundles}
+	httputils.JSONResponse(w, response)
+}
+
+func (h *Handler) registerBundle(w http.ResponseWriter, r *http.Request) {
+	var req client.RegisterBundleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httputils.JSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	bundle := &client.BundleResponse{Name: req.Name}
+	httputils.JSONResponseWithCode(w, http.StatusCreated, bundle)
+}
+
+func (h *Handler) getBundle(w http.ResponseWriter, r *http.Request) {
+	var bundle client.BundleResponse
+	httputils.JSONResponse(w, bundle)
+}
+
+func (h *Handler) deploy(w http.ResponseWriter, r *http.Request) {
+	req := new(deployRequest)
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		httputils.JSONError(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	httputils.JSONResponseWithCode(w, http.StatusAccepted, &client.DeployResponse{})
+}
+
+func (h *Handler) getDeployment() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deployments := []client.DeployResponse{}
+		json.NewEncoder(w).Encode(deployments)
+	}
+}
+
+func (h *Handler) undeploy(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
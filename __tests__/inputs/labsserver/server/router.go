@@ -0,0 +1,38 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/contiamo/labs/pkg/labsserver/other"
+	buildinfo "github.com/contiamo/labs/pkg/labsserver/version"
+	"github.com/go-chi/chi"
+	"github.com/gorilla/mux"
+)
+
+// NewRouter registers the bundles API
+func (h *Handler) NewRouter() http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc("/healthz", h.health)
+	r.HandleFunc("/version", buildinfo.Get)
+	r.HandleFunc("/things", other.ListThings).Methods(http.MethodGet)
+
+	api := r.PathPrefix("/api/v1").Subrouter()
+	api.HandleFunc("/bundles", h.listBundles).Methods(http.MethodGet)
+	api.HandleFunc("/bundles", h.registerBundle).Methods("POST")
+	api.Handle("/bundles/{id:[0-9a-f-]+}", http.HandlerFunc(h.getBundle)).Methods(http.MethodGet, http.MethodHead)
+
+	return r
+}
+
+// NewDeploymentsRouter registers the deployments API
+func (h *Handler) NewDeploymentsRouter() http.Handler {
+	r := chi.NewRouter()
+	r.Route("/api/v1/deployments", func(r chi.Router) {
+		r.Post("/", h.deploy)
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.getDeployment())
+			r.Delete("/", h.undeploy)
+		})
+	})
+	return r
+}
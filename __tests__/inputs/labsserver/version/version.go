@@ -0,0 +1,17 @@
+package version
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Response describes the running server
+type Response struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+}
+
+// Get writes the version of the server
+func Get(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(Response{Version: "dev"})
+}
@@ -0,0 +1,23 @@
+package maps
+
+// JobState describes the state of a job
+type JobState string
+
+const (
+	// JobPending indicates that the job is waiting for a runner
+	JobPending JobState = "pending"
+	// JobDone indicates that the job is finished
+	JobDone = "done"
+)
+
+// Priority of a job
+type Priority int32
+
+// JobStats aggregates the jobs of a bundle
+type JobStats struct {
+	ByState    map[JobState]int                `json:"byState"`
+	ByPriority map[Priority][]string           `json:"byPriority"`
+	ByHour     map[int]JobState                `json:"byHour"`
+	Labels     map[string][]map[string]int     `json:"labels"`
+	Runners    []map[string]map[JobState]int64 `json:"runners"`
+}
@@ -0,0 +1,18 @@
+package naming
+
+// HTTPError is the error returned by the API
+type HTTPError struct {
+	Status  int              `json:"status"`
+	Message jsonErrorMessage `json:"message"`
+	Details []ErrorDetail    `json:"details"`
+}
+
+type jsonErrorMessage struct {
+	Text string `json:"text"`
+}
+
+// ErrorDetail describes an invalid field
+//go2dts:name FieldError
+type ErrorDetail struct {
+	Field string `json:"field"`
+}
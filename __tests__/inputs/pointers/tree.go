@@ -0,0 +1,12 @@
+package pointers
+
+// Node is a node of a bundle tree
+type Node struct {
+	Name     string            `json:"name"`
+	Children []*Node           `json:"children"`
+	Siblings *[]Node           `json:"siblings"`
+	Levels   []*[]string       `json:"levels"`
+	Index    map[string]*Node  `json:"index"`
+	Grid     [2][]**int        `json:"grid"`
+	Paths    map[int][]*string `json:"paths"`
+}
@@ -0,0 +1,63 @@
+package events
+
+type Command struct {
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Types that are valid to be assigned to Action:
+	//	*Command_Start
+	//	*Command_Stop
+	Action isCommand_Action `protobuf_oneof:"action"`
+	// Types that are valid to be assigned to Target:
+	//	*Command_BundleId
+	//	*Command_JobName
+	Target isCommand_Target `protobuf_oneof:"target"`
+}
+
+type isCommand_Action interface {
+	isCommand_Action()
+}
+
+type Command_Start struct {
+	Start *StartAction `protobuf:"bytes,2,opt,name=start,proto3,oneof"`
+}
+
+type Command_Stop struct {
+	Stop *StopAction `protobuf:"bytes,3,opt,name=stop,proto3,oneof"`
+}
+
+func (*Command_Start) isCommand_Action() {}
+
+func (*Command_Stop) isCommand_Action() {}
+
+type isCommand_Target interface {
+	isCommand_Target()
+}
+
+type Command_BundleId struct {
+	BundleId string `protobuf:"bytes,4,opt,name=bundle_id,json=bundleId,proto3,oneof"`
+}
+
+type Command_JobName struct {
+	JobName string `protobuf:"bytes,5,opt,name=job_name,json=jobName,proto3,oneof"`
+}
+
+func (*Command_BundleId) isCommand_Target() {}
+
+func (*Command_JobName) isCommand_Target() {}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*Command) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*Command_Start)(nil),
+		(*Command_Stop)(nil),
+		(*Command_BundleId)(nil),
+		(*Command_JobName)(nil),
+	}
+}
+
+type StartAction struct {
+	Force bool `protobuf:"varint,1,opt,name=force,proto3" json:"force,omitempty"`
+}
+
+type StopAction struct {
+	Reason string `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+}
@@ -0,0 +1,24 @@
+package events
+
+import (
+	anypb "google.golang.org/protobuf/types/known/anypb"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	wrapperspb "google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type Event struct {
+	Id         string                  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt  *timestamppb.Timestamp  `protobuf:"bytes,2,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	Timeout    *durationpb.Duration    `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Label      *wrapperspb.StringValue `protobuf:"bytes,4,opt,name=label,proto3" json:"label,omitempty"`
+	Retries    *wrapperspb.Int64Value  `protobuf:"bytes,5,opt,name=retries,proto3" json:"retries,omitempty"`
+	Attributes *structpb.Struct        `protobuf:"bytes,6,opt,name=attributes,proto3" json:"attributes,omitempty"`
+	Payload    *anypb.Any              `protobuf:"bytes,7,opt,name=payload,proto3" json:"payload,omitempty"`
+	Mask       *fieldmaskpb.FieldMask  `protobuf:"bytes,8,opt,name=mask,proto3" json:"mask,omitempty"`
+	Ack        *emptypb.Empty          `protobuf:"bytes,9,opt,name=ack,proto3" json:"ack,omitempty"`
+	Size       int64                   `protobuf:"varint,10,opt,name=size,proto3" json:"size,omitempty"`
+}
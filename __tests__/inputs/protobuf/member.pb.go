@@ -0,0 +1,7 @@
+package events
+
+type Member struct {
+	DisplayName   string `protobuf:"bytes,1,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	TenantId      string `protobuf:"bytes,2,opt,name=tenant_id,proto3" json:"tenant_id"`
+	XXX_sizecache int32  `json:"XXX_sizecache,omitempty"`
+}
@@ -0,0 +1,10 @@
+package events
+
+// Priority has no value yet
+type Priority int32
+
+// Enum value maps for Priority.
+var (
+	Priority_name  = map[int32]string{}
+	Priority_value = map[string]int32{}
+)
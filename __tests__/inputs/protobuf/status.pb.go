@@ -0,0 +1,28 @@
+package events
+
+type JobStatus int32
+
+const (
+	JobStatus_UNKNOWN JobStatus = 0
+	JobStatus_RUNNING JobStatus = 1
+	JobStatus_DONE    JobStatus = 2
+)
+
+// Enum value maps for JobStatus.
+var (
+	JobStatus_name = map[int32]string{
+		0: "UNKNOWN",
+		1: "RUNNING",
+		2: "DONE",
+	}
+	JobStatus_value = map[string]int32{
+		"UNKNOWN": 0,
+		"RUNNING": 1,
+		"DONE":    2,
+	}
+)
+
+type Job struct {
+	Name   string    `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Status JobStatus `protobuf:"varint,2,opt,name=status,proto3,enum=events.JobStatus" json:"status,omitempty"`
+}
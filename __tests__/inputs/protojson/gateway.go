@@ -0,0 +1,17 @@
+package events
+
+import (
+	"net/http"
+
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// WriteEvent writes an event with the proto3 JSON mapping
+func WriteEvent(w http.ResponseWriter, event *Event) error {
+	payload, err := protojson.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(payload)
+	return err
+}
@@ -0,0 +1,20 @@
+package scalars
+
+import "time"
+
+// UUIDList is a list of job identifiers
+type UUIDList []string
+
+// LastRunTime describes the last execution of a job
+type LastRunTime struct {
+	Jobs     UUIDList `json:"jobs"`
+	Duration Duration `json:"duration"`
+	// CheckedAt is only used by the scheduler
+	CheckedAt time.Time `json:"-"`
+}
+
+// Schedule describes when a job is executed
+type Schedule struct {
+	Cron    string      `json:"cron"`
+	LastRun LastRunTime `json:"lastRun"`
+}
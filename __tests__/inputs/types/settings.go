@@ -0,0 +1,52 @@
+package types
+
+// TenantID identifies a labs tenant
+type TenantID string
+
+// Settings contains the user preferences of the labs UI
+type Settings struct {
+	Name   string `json:"name"`
+	Config Bundle `json:"config" tstype:"Partial<Bundle>"`
+	Theme  string `json:"theme" ts:"\"light\" | \"dark\""`
+	// Locale is set by the UI on the first login
+	//go2dts:optional
+	Locale string `json:"locale"`
+	//go2dts:ignore
+	Secret string            `json:"secret"`
+	Labels map[string]string `json:"labels"` //go2dts:type Record<string, string>
+	//go2dts:name displayName
+	Title     string    `json:"title"`
+	Shortcuts *[]string `json:"shortcuts,omitempty"`
+	Version   int64     `json:"version"`
+	Revision  uint64    `json:"revision,string"`
+	Tenant    TenantID  `json:"tenant"`
+}
+
+// SettingsCache is only used by the server
+//go2dts:ignore
+type SettingsCache struct {
+	Entries []Settings `json:"entries"`
+}
+
+// SettingsPatch is the payload to update the user settings
+//go2dts:name PartialSettings
+//go2dts:optional
+type SettingsPatch struct {
+	Name  string `json:"name"`
+	Theme string `json:"theme"`
+}
+
+// Color is serialized as an hexadecimal string
+//go2dts:type string
+type Color struct {
+	R uint8 `json:"r"`
+	G uint8 `json:"g"`
+	B uint8 `json:"b"`
+}
+
+// SettingsUpdateRequest is the payload to replace the user settings
+type SettingsUpdateRequest struct {
+	//go2dts:required
+	Name     string   `json:"name"`
+	Settings Settings `json:"settings"`
+}
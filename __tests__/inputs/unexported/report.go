@@ -0,0 +1,35 @@
+package unexported
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Report summarizes the jobs of a bundle
+type Report struct {
+	Entries []entry `json:"entries"`
+}
+
+type entry struct {
+	Job string `json:"job"`
+}
+
+type cacheItem struct {
+	Key string `json:"key"`
+}
+
+type errorPayload struct {
+	Message string `json:"message"`
+}
+
+// options are sent by the UI
+//go2dts:export
+type options struct {
+	Verbose bool `json:"verbose"`
+}
+
+// ReportError writes an error as JSON
+func ReportError(w http.ResponseWriter, err error) {
+	payload := errorPayload{Message: err.Error()}
+	json.NewEncoder(w).Encode(payload)
+}
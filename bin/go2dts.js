@@ -2,6 +2,7 @@
 
 const program = require("commander");
 const go2dts = require("../src/index");
+const loadConfig = require("../src/config");
 const { readFileSync } = require("fs");
 const { join } = require("path");
 
@@ -11,13 +12,17 @@ const package = JSON.parse(
 
 program
   .version(package.version)
-  .usage("<inputDirs ...> <outputDirOrFile>")
+  .usage("[options] <inputDirs ...> <outputDirOrFile>")
+  .option(
+    "-c, --config <file>",
+    "configuration file (default: go2dts.config.json or go2dts.config.js)"
+  )
   .action((...args) => {
     const currentDir = process.cwd();
     const inputDirs = args.slice(0, -2).map(i => join(currentDir, i));
     const outputDirOrFile = join(currentDir, args[args.length - 2]);
 
-    go2dts(inputDirs, outputDirOrFile);
+    go2dts(inputDirs, outputDirOrFile, loadConfig(program.config, currentDir));
     console.log(`Types definition created into ${outputDirOrFile}`);
   })
   .parse(process.argv);
@@ -0,0 +1,45 @@
+/**
+ * Branded (nominal) version of a type
+ *
+ * @param {string} name
+ * @param {string} type
+ * @return {string}
+ */
+const brand = (name, type) => `${type} & { readonly __brand: "${name}" }`;
+
+// Runtime validation of the well-known scalars
+const validators = {
+  UUID: `/^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$/i.test(value)`,
+  Time: `!isNaN(Date.parse(value))`,
+  Int64: `/^-?\\d+$/.test(value)`
+};
+
+/**
+ * Emit the guard (`isX`) and parse (`parseX`) helpers of the branded types
+ *
+ * @param {string[]} names branded types
+ * @param {string} typesModule path of the module declaring the types
+ * @return {string} typescript module
+ */
+const emitBrandHelpers = (names, typesModule) =>
+  `// Generated by go2dts
+
+import { ${names.join(", ")} } from "${typesModule}";
+` +
+  names
+    .map(
+      name => `
+export const is${name} = (value: unknown): value is ${name} =>
+  typeof value === "string"${
+    validators[name] ? ` && ${validators[name]}` : ""
+  };
+
+export const parse${name} = (value: string): ${name} => {
+  if (!is${name}(value)) throw new TypeError(\`Invalid ${name}: "\${value}"\`);
+  return value;
+};
+`
+    )
+    .join("");
+
+module.exports = { brand, emitBrandHelpers };
@@ -0,0 +1,416 @@
+// Status codes of the `net/http` constants
+const statusCodes = {
+  StatusOK: 200,
+  StatusCreated: 201,
+  StatusAccepted: 202,
+  StatusNoContent: 204,
+  StatusMovedPermanently: 301,
+  StatusFound: 302,
+  StatusNotModified: 304,
+  StatusBadRequest: 400,
+  StatusUnauthorized: 401,
+  StatusPaymentRequired: 402,
+  StatusForbidden: 403,
+  StatusNotFound: 404,
+  StatusMethodNotAllowed: 405,
+  StatusConflict: 409,
+  StatusGone: 410,
+  StatusPreconditionFailed: 412,
+  StatusUnprocessableEntity: 422,
+  StatusTooManyRequests: 429,
+  StatusInternalServerError: 500,
+  StatusNotImplemented: 501,
+  StatusBadGateway: 502,
+  StatusServiceUnavailable: 503,
+  StatusGatewayTimeout: 504
+};
+
+/**
+ * Parameters of a go function (`ctx context.Context, a, b string`), without
+ * the contexts
+ *
+ * @param {string} params
+ * @return {object[]} `{ name, type }`
+ */
+function parseParams(params) {
+  let type;
+  return params
+    .split(",")
+    .map(i => i.trim())
+    .filter(Boolean)
+    .reverse()
+    .map(param => {
+      const [name, paramType] = param.split(/\s+/);
+      type = paramType || type;
+      return { name, type };
+    })
+    .reverse()
+    .filter(({ type }) => type !== "context.Context");
+}
+
+/**
+ * Extract the HTTP clients of a go file, from the methods sending a request
+ * (`http.NewRequest`) built by an URL helper:
+ *
+ * ```go
+ * func (cli *bundleClientImpl) getURL(optionals ...string) string {
+ *   args := []string{cli.labsAddr, "api", "bundles"}
+ *   args = append(args, optionals...)
+ *   return strings.Join(args, "/")
+ * }
+ * ```
+ *
+ * Clients are `{ kind: "client", name, goName, pkg, options, base, fields }`:
+ *  - `options`: the fields of the go struct used by the URL (`labsAddr`)
+ *  - `base`: the segments of the URL (`{ option }` or `{ literal }`)
+ *  - `fields`: the methods, `type` being their signature, with the `method`,
+ *    the `segments` of the URL, the `body` and `headers` of the request, the
+ *    `statuses` handled (`{ codes, result }`, the result being `decode`,
+ *    `empty` or an error message) and the `pluck`ed property of the response
+ *
+ * @param {string} data go source
+ * @param {object} options
+ * @param {string} options.pkg go package
+ * @param {function} options.resolveReference go type -> typescript type
+ * @return {object[]} declarations
+ */
+function parseClients(data, { pkg, resolveReference }) {
+  const tsType = type => {
+    if (/^\*/.test(type)) return tsType(type.slice(1));
+    if (/^\[\]/.test(type)) return `${tsType(type.slice(2))}[]`;
+    return resolveReference(type);
+  };
+
+  // URL helpers (receiver type -> helper -> segments)
+  const urlHelpers = {};
+  const helperRegex = /^func \((\w+) \*(\w+)\) (\w+)\((\w+) \.\.\.string\) string {\n([^]*?)\n}/gm;
+  let m;
+  while ((m = helperRegex.exec(data)) !== null) {
+    const [, receiver, goName, helper, , body] = m;
+    const [, list] = /\[\]string{([^}]*)}/.exec(body) || [];
+    if (!list || !/strings\.Join\(\w+, "\/"\)/.test(body)) continue;
+    const segments = list
+      .split(",")
+      .map(i => i.trim())
+      .filter(Boolean)
+      .map(segment => {
+        if (/^"/.test(segment)) return { literal: JSON.parse(segment) };
+        const field = segment.replace(new RegExp(`^${receiver}\\.`), "");
+        return { option: field.charAt(0).toLowerCase() + field.slice(1) };
+      });
+    urlHelpers[goName] = Object.assign(urlHelpers[goName] || {}, {
+      [helper]: segments
+    });
+  }
+
+  const clients = {};
+  const methodRegex = /^func \((\w+) \*(\w+)\) ([A-Z]\w*)\(([^)]*)\) ([^{]*?)\s*{\n([^]*?)\n}/gm;
+  while ((m = methodRegex.exec(data)) !== null) {
+    const [, receiver, goName, method, params, results, body] = m;
+    const request = /http\.NewRequest(?:WithContext)?\((?:\w+, )?(?:http\.Method(\w+)|"(\w+)"), ([^,]+), ([^\n]+)\)\n/.exec(
+      body
+    );
+    if (!request || !urlHelpers[goName]) continue;
+    const [, constant, literal, url, requestBody] = request;
+    const args = parseParams(params);
+
+    // URL: `cli.getURL(id.String(), "sync")`, possibly through a variable
+    const urlCall = new RegExp(
+      `${receiver}\\.(\\w+)\\(((?:[^()]|\\(\\))*)\\)`
+    ).exec(
+      (new RegExp(`\\b${url} :?= (.*)`).exec(body) || [])[1] || url
+    );
+    if (!urlCall || !urlHelpers[goName][urlCall[1]]) continue;
+    const segments = urlCall[2]
+      .split(",")
+      .map(i => i.trim())
+      .filter(Boolean)
+      .map(segment =>
+        /^"/.test(segment)
+          ? { literal: JSON.parse(segment) }
+          : { param: segment.replace(/\.String\(\)$/, "") }
+      );
+
+    // Request body: `json.Marshal(req)` of a parameter
+    const [, marshalled, bodyParam] =
+      /\b(\w+), err :?= json\.Marshal\(&?(\w+)\)/.exec(body) || [];
+    const sendsBody =
+      requestBody !== "nil" &&
+      marshalled &&
+      requestBody.includes(marshalled) &&
+      args.some(i => i.name === bodyParam);
+
+    const headers = {};
+    const headerRegex = /\.Header\.Set\("([^"]+)", "([^"]*)"\)/g;
+    let h;
+    while ((h = headerRegex.exec(body)) !== null) headers[h[1]] = h[2];
+
+    // Response: the first result (`(*BundleResponse, error)`), or a property
+    // of the decoded value (`return urlStruct.URL, nil`)
+    const result = results
+      .replace(/^\(|\)$/g, "")
+      .split(",")
+      .map(i => i.trim().split(/\s+/).pop())
+      .find(i => i && i !== "error");
+    const [, decoded] =
+      /json\.NewDecoder\(\w+\.Body\)\.Decode\(&?(\w+)\)/.exec(body) || [];
+    let pluck;
+    const [, property] = decoded
+      ? new RegExp(`return ${decoded}\\.(\\w+), nil`).exec(body) || []
+      : [];
+    if (property) {
+      const [, fields = ""] =
+        new RegExp(`${decoded} :?= &?struct {([^}]*)}`).exec(body) || [];
+      const tag = new RegExp(`\\b${property} \\S+ \`json:"([^",]*)`).exec(
+        fields
+      );
+      pluck = tag ? tag[1] : property;
+    }
+
+    // Handled statuses: `switch resp.StatusCode { case http.StatusOK: ... }`
+    const statuses = [];
+    const [, cases] = /switch \w+\.StatusCode {\n([^]*?)\n\t}/.exec(body) || [];
+    (cases || "")
+      .split(/\n\t(?=case |default:)/)
+      .map(i => i.trim())
+      .filter(i => /^case /.test(i))
+      .forEach(i => {
+        const [, list, block] = /^case ([^:]*):([^]*)$/.exec(i);
+        const codes = list
+          .split(",")
+          .map(code => statusCodes[code.trim().replace(/^http\./, "")])
+          .filter(Boolean);
+        const [, message] = /errors\.New\("([^"]*)"\)/.exec(block) || [];
+        const succeeds = /return (nil|"", nil|nil, nil)?$/m.test(block);
+        const outcome = /\.Decode\(/.test(block)
+          ? "decode"
+          : message === undefined && succeeds
+          ? "empty"
+          : message || "unexpected";
+        if (codes.length) statuses.push({ codes, result: outcome });
+      });
+
+    const signature = `(${args
+      .map(i => `${i.name}: ${tsType(i.type)}`)
+      .join(", ")}): Promise<${result ? tsType(result) : "void"}>`;
+
+    const client = clients[goName] || {
+      kind: "client",
+      name: goName,
+      goName,
+      pkg,
+      options: urlHelpers[goName][urlCall[1]]
+        .filter(i => i.option)
+        .map(i => i.option),
+      base: urlHelpers[goName][urlCall[1]],
+      fields: []
+    };
+    clients[goName] = client;
+    client.fields.push({
+      name: method.charAt(0).toLowerCase() + method.slice(1),
+      type: signature,
+      method: (constant || literal).toUpperCase(),
+      segments,
+      body: sendsBody ? bodyParam : undefined,
+      headers,
+      statuses,
+      decode: Boolean(decoded),
+      pluck,
+      optional: false,
+      required: false,
+      nullable: false
+    });
+  }
+  return Object.keys(clients).map(goName => clients[goName]);
+}
+
+/**
+ * Emit the fetch clients module
+ *
+ * @param {object[]} clients client declarations (see `parseClients`)
+ * @param {string[]} names names exported by the types module
+ * @param {string} typesModule path of the module declaring the types
+ * @param {string} [apiError] name of the `ApiError` declaration, the
+ * unexpected statuses carry it parsed (see `emitApiErrorHelpers`)
+ * @return {string} typescript module
+ */
+function emitClients(clients, names, typesModule, apiError) {
+  const imports = names
+    .filter(name =>
+      clients.some(client =>
+        client.fields.some(f => new RegExp(`\\b${name}\\b`).test(f.type))
+      )
+    )
+    .concat(apiError || []);
+
+  const emitMethod = field => {
+    const path = field.segments
+      .map(i => (i.literal ? JSON.stringify(i.literal) : `String(${i.param})`))
+      .join(", ");
+    const init = [`method: "${field.method}"`];
+    if (Object.keys(field.headers).length) {
+      const headers = Object.keys(field.headers).map(
+        key => `${JSON.stringify(key)}: ${JSON.stringify(field.headers[key])}`
+      );
+      init.push(`headers: { ${headers.join(", ")} }`);
+    }
+    if (field.body) init.push(`body: JSON.stringify(${field.body})`);
+
+    // The field errors of the `ApiError` are keyed by the request properties
+    const [, bodyType] =
+      (apiError && new RegExp(`\\b${field.body}: ([^,)]+)`).exec(field.type)) ||
+      [];
+    const unexpected = `throw await unexpectedStatus${
+      bodyType ? `<${bodyType}>` : ""
+    }(response);`;
+
+    const success = field.decode
+      ? field.pluck
+        ? `return (await response.json()).${field.pluck};`
+        : "return response.json();"
+      : "return;";
+    const branches = field.statuses.map(({ codes, result }) => {
+      const action =
+        result === "decode" || result === "empty"
+          ? success
+          : result === "unexpected"
+          ? unexpected
+          : `throw new ClientError(response.status, ${JSON.stringify(
+              result
+            )});`;
+      return { codes, action };
+    });
+
+    const handling = !branches.length
+      ? `    if (!response.ok) ${unexpected}
+    ${success}`
+        : `    switch (response.status) {
+${branches
+          .map(
+            ({ codes, action }) =>
+              codes.map(code => `      case ${code}:\n`).join("") +
+              `        ${action}`
+          )
+          .join("\n")}
+      default:
+        ${unexpected}
+    }`;
+
+    return `
+  async ${field.name}${field.type} {
+    const response = await this.fetch(this.url(${path}), {
+      ${init.join(",\n      ")}
+    });
+${handling}
+  }
+`;
+  };
+
+  return (
+    `// Generated by go2dts
+${
+      imports.length
+        ? `
+import {
+${imports.map(name => `  ${name}`).join(",\n")}
+} from "${typesModule}";
+`
+        : ""
+    }${
+      apiError
+        ? `import { parseApiError } from "${typesModule}.errors";
+`
+        : ""
+    }
+${apiError ? emitApiErrorStatus(apiError) : emitStatus()}` +
+    clients
+      .map(
+        client => `
+export interface ${client.name}Options {
+${client.options.map(i => `  ${i}: string;`).join("\n")}
+  fetch?: typeof fetch;
+}
+
+export class ${client.name} {
+  constructor(private readonly options: ${client.name}Options) {}
+
+  private url(...segments: string[]): string {
+    return [
+${client.base
+          .map(i =>
+            i.literal
+              ? `      ${JSON.stringify(i.literal)},`
+              : `      this.options.${i.option},`
+          )
+          .join("\n")}
+      ...segments
+    ].join("/");
+  }
+
+  private fetch(url: string, init: RequestInit): Promise<Response> {
+    return (this.options.fetch || fetch)(url, init);
+  }
+${client.fields.map(emitMethod).join("")}}
+`
+      )
+      .join("")
+  );
+}
+
+/**
+ * `ClientError` and `unexpectedStatus`, with the message of the response
+ *
+ * @return {string}
+ */
+const emitStatus = () =>
+  `export class ClientError extends Error {
+  constructor(readonly status: number, message: string) {
+    super(message);
+  }
+}
+
+const unexpectedStatus = async (response: Response) => {
+  const message = await response.text().catch(() => "");
+  return new ClientError(
+    response.status,
+    \`unexpected status code \${response.status}\${
+      message ? \`, message: \${message}\` : ""
+    }\`
+  );
+};
+`;
+
+/**
+ * `ClientError` and `unexpectedStatus`, with the message and the parsed
+ * `ApiError` of the response
+ *
+ * @param {string} name name of the `ApiError` declaration
+ * @return {string}
+ */
+const emitApiErrorStatus = name =>
+  `export class ClientError<T = {[key: string]: unknown}> extends Error {
+  constructor(
+    readonly status: number,
+    message: string,
+    readonly apiError?: ${name}<T>
+  ) {
+    super(message);
+  }
+}
+
+const unexpectedStatus = async <T = {[key: string]: unknown}>(
+  response: Response
+) => {
+  const apiError = await parseApiError<T>(response);
+  const message = await response.text().catch(() => "");
+  return new ClientError<T>(
+    response.status,
+    \`unexpected status code \${response.status}\${
+      message ? \`, message: \${message}\` : ""
+    }\`,
+    apiError
+  );
+};
+`;
+
+module.exports = { emitClients, parseClients };
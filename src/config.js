@@ -0,0 +1,26 @@
+const { existsSync, readFileSync } = require("fs");
+const { extname, join, resolve } = require("path");
+
+const defaultConfigFiles = ["go2dts.config.json", "go2dts.config.js"];
+
+/**
+ * Load the go2dts configuration.
+ *
+ * Without explicit path, a `go2dts.config.json` or `go2dts.config.js`
+ * is searched into the current working directory.
+ *
+ * @param {string} [configPath]
+ * @param {string} [cwd]
+ */
+const loadConfig = (configPath, cwd = process.cwd()) => {
+  const path = configPath
+    ? resolve(cwd, configPath)
+    : defaultConfigFiles.map(fileName => join(cwd, fileName)).find(existsSync);
+
+  if (!path) return {};
+  if (!existsSync(path)) throw new Error(`${path} doesn't exist`);
+  if (extname(path) === ".json") return JSON.parse(readFileSync(path, "utf-8"));
+  return require(path);
+};
+
+module.exports = loadConfig;
@@ -0,0 +1,293 @@
+const { fieldType } = require("./emit");
+
+// Scalars decoded to `Date`
+const dateTypes = ["Time", "Timestamp"];
+
+/**
+ * Split a typescript type on a separator, ignoring the nested ones
+ *
+ * @param {string} type
+ * @param {string} separator
+ * @return {string[]}
+ */
+function splitTopLevel(type, separator) {
+  const parts = [];
+  let depth = 0;
+  let quoted = false;
+  let current = "";
+  for (const char of type) {
+    if (char === `"`) quoted = !quoted;
+    if (!quoted && "([{<".includes(char)) depth++;
+    if (!quoted && ")]}>".includes(char)) depth--;
+    if (!quoted && depth === 0 && char === separator) {
+      parts.push(current.trim());
+      current = "";
+    } else {
+      current += char;
+    }
+  }
+  return parts.concat(current.trim());
+}
+
+/**
+ * Parse the typescript types emitted by go2dts
+ *
+ * Nodes are one of:
+ *  - `{ kind: "union", types }`
+ *  - `{ kind: "array", element }`
+ *  - `{ kind: "tuple", elements }`
+ *  - `{ kind: "map", key, value, wrapper }` (`index`, `record` or `partial`)
+ *  - `{ kind: "ref", name }`
+ *  - `{ kind: "opaque", text }` (everything else, never decoded)
+ *
+ * @param {string} type
+ * @return {object}
+ */
+function parseTsType(type) {
+  const text = type.trim();
+
+  const union = splitTopLevel(text, "|");
+  if (union.length > 1) return { kind: "union", types: union.map(parseTsType) };
+
+  if (/^\(.*\)$/.test(text) && splitTopLevel(text, ",").length === 1) {
+    return parseTsType(text.slice(1, -1));
+  }
+  if (/\[\]$/.test(text)) {
+    return { kind: "array", element: parseTsType(text.slice(0, -2)) };
+  }
+  if (/^\[.*\]$/.test(text)) {
+    const elements = splitTopLevel(text.slice(1, -1), ",").map(parseTsType);
+    return { kind: "tuple", elements };
+  }
+
+  const index = /^{\s*\[key: ([^\]]+)\]: ([^]*)}$/.exec(text);
+  if (index) {
+    const value = parseTsType(index[2]);
+    return { kind: "map", key: index[1], value, wrapper: "index" };
+  }
+  const record = /^(Partial<)?Record<([^]*)>(>?)$/.exec(text);
+  if (record && Boolean(record[1]) === Boolean(record[3])) {
+    const [key, value] = splitTopLevel(record[2], ",");
+    const wrapper = record[1] ? "partial" : "record";
+    return { kind: "map", key, value: parseTsType(value), wrapper };
+  }
+
+  if (/^[A-Za-z_$][\w$]*$/.test(text)) return { kind: "ref", name: text };
+  return { kind: "opaque", text };
+}
+
+/**
+ * Print a parsed type, with the decoded types
+ *
+ * @param {object} node
+ * @param {Set<string>} decodable names of the decodable interfaces
+ * @return {string}
+ */
+function printDecoded(node, decodable) {
+  switch (node.kind) {
+    case "union":
+      return node.types.map(i => printDecoded(i, decodable)).join(" | ");
+    case "array":
+      const element = printDecoded(node.element, decodable);
+      return node.element.kind === "union" ? `(${element})[]` : `${element}[]`;
+    case "tuple":
+      return `[${node.elements
+        .map(i => printDecoded(i, decodable))
+        .join(", ")}]`;
+    case "map":
+      const value = printDecoded(node.value, decodable);
+      if (node.wrapper === "index") return `{[key: ${node.key}]: ${value}}`;
+      if (node.wrapper === "record") return `Record<${node.key}, ${value}>`;
+      return `Partial<Record<${node.key}, ${value}>>`;
+    case "ref":
+      if (dateTypes.includes(node.name)) return "Date";
+      return decodable.has(node.name) ? `Decoded${node.name}` : node.name;
+    default:
+      return node.text;
+  }
+}
+
+/**
+ * Expression decoding a value, `undefined` if the value don't need any decoding
+ *
+ * @param {object} node
+ * @param {string} value expression of the value to decode
+ * @param {Set<string>} decodable names of the decodable interfaces
+ * @param {number} depth nesting depth (to name the callback parameters)
+ * @return {string|undefined}
+ */
+function decodeExpression(node, value, decodable, depth = 0) {
+  switch (node.kind) {
+    case "union":
+      const nullish = node.types.filter(
+        i => i.kind === "ref" && ["null", "undefined"].includes(i.name)
+      );
+      if (node.types.length - nullish.length !== 1) return;
+      const type = node.types.find(i => !nullish.includes(i));
+      const expression = decodeExpression(type, value, decodable, depth);
+      // The arrays and the maps are already guarded
+      if (!expression || ["array", "map"].includes(type.kind)) {
+        return expression;
+      }
+      return `${value} == null ? ${value} : ${expression}`;
+    case "array":
+      const element = decodeExpression(
+        node.element,
+        `i${depth}`,
+        decodable,
+        depth + 1
+      );
+      // Guarded whatever their type: go encodes the nil slices as `null`
+      return (
+        element &&
+        `${value} == null ? ${value} : ${value}.map(i${depth} => ${element})`
+      );
+    case "tuple":
+      const elements = node.elements.map((i, index) =>
+        decodeExpression(i, `${value}[${index}]`, decodable, depth)
+      );
+      if (!elements.some(Boolean)) return;
+      return `[${elements
+        .map((i, index) => i || `${value}[${index}]`)
+        .join(", ")}] as [${node.elements
+        .map(i => printDecoded(i, decodable))
+        .join(", ")}]`;
+    case "map":
+      const item = decodeExpression(
+        node.value,
+        `i${depth}`,
+        decodable,
+        depth + 1
+      );
+      return (
+        item &&
+        `${value} == null ? ${value} : mapValues(${value}, i${depth} => ${item})`
+      );
+    case "ref":
+      if (dateTypes.includes(node.name)) return `new Date(${value})`;
+      if (decodable.has(node.name)) return `decode${node.name}(${value})`;
+  }
+}
+
+/**
+ * Names of the interfaces containing (at any depth) a date
+ *
+ * @param {object[]} declarations
+ * @return {Set<string>}
+ */
+function decodableTypes(declarations) {
+  const interfaces = declarations.filter(d => d.kind === "interface");
+  const decodable = new Set();
+  let size;
+  do {
+    size = decodable.size;
+    interfaces
+      .filter(
+        d =>
+          decodable.has(d.parent) ||
+          d.fields.some(f =>
+            decodeExpression(parseTsType(fieldType(f)), "json", decodable)
+          )
+      )
+      .forEach(d => decodable.add(d.name));
+  } while (decodable.size !== size);
+  return decodable;
+}
+
+/**
+ * Decoded version of an interface (dates as `Date`)
+ *
+ * @param {object} declaration
+ * @param {Set<string>} decodable names of the decodable interfaces
+ * @return {object} declaration
+ */
+function decodedDeclaration(declaration, decodable) {
+  return Object.assign({}, declaration, {
+    name: `Decoded${declaration.name}`,
+    parent:
+      declaration.parent && decodable.has(declaration.parent)
+        ? `Decoded${declaration.parent}`
+        : declaration.parent,
+    fields: declaration.fields.map(field =>
+      Object.assign({}, field, {
+        type: printDecoded(parseTsType(field.type), decodable)
+      })
+    )
+  });
+}
+
+/**
+ * Emit the decoders module (`decodeX(json)` for every decodable interface)
+ *
+ * @param {object[]} declarations
+ * @param {Set<string>} decodable names of the decodable interfaces
+ * @param {string} typesModule path of the module declaring the types
+ * @return {string} typescript module
+ */
+function emitDecoders(declarations, decodable, typesModule) {
+  const interfaces = declarations.filter(
+    d => d.kind === "interface" && decodable.has(d.name)
+  );
+  const names = interfaces
+    .map(d => d.name)
+    .reduce((mem, name) => mem.concat(name, `Decoded${name}`), []);
+
+  return (
+    `// Generated by go2dts
+
+import {
+${names.map(name => `  ${name}`).join(",\n")}
+} from "${typesModule}";
+
+const mapValues = <T, U>(
+  values: { [key: string]: T },
+  decode: (value: T) => U
+) =>
+  Object.keys(values).reduce(
+    (mem, key) => {
+      mem[key] = decode(values[key]);
+      return mem;
+    },
+    {} as { [key: string]: U }
+  );
+` +
+    interfaces
+      .map(d => {
+        const fields = d.fields
+          .map(f => {
+            const identifier = /^[A-Za-z_$][\w$]*$/.test(f.name);
+            const key = identifier ? f.name : JSON.stringify(f.name);
+            const access = identifier ? `json.${f.name}` : `json[${key}]`;
+            const expression = decodeExpression(
+              parseTsType(
+                `${fieldType(f)}${f.optional || f.oneof ? " | undefined" : ""}`
+              ),
+              access,
+              decodable
+            );
+            return expression && `    ${key}: ${expression}`;
+          })
+          .filter(Boolean);
+        const spread = ["    ...json"]
+          .concat(
+            decodable.has(d.parent) ? `    ...decode${d.parent}(json)` : []
+          )
+          .concat(fields);
+        return `
+export function decode${d.name}(json: ${d.name}): Decoded${d.name} {
+  return {
+${spread.join(",\n")}
+  };
+}
+`;
+      })
+      .join("")
+  );
+}
+
+module.exports = {
+  decodableTypes,
+  decodedDeclaration,
+  emitDecoders,
+  parseTsType
+};
@@ -0,0 +1,172 @@
+const defaultNaming = {
+  input: "Request$",
+  output: "Resp(onse)?$"
+};
+
+/**
+ * Merge two directions (`input` + `output` = `both`)
+ *
+ * @param {string} [a]
+ * @param {string} [b]
+ * @return {string}
+ */
+const merge = (a, b) => (!a || a === b ? b : !b ? a : "both");
+
+/**
+ * Replace the references to the `both` types by their variant
+ *
+ * @param {string} type typescript type
+ * @param {string[]} names types to replace
+ * @param {string} suffix `Input` or `Output`
+ * @return {string}
+ */
+const useVariant = (type, names, suffix) =>
+  names.reduce(
+    (mem, name) => mem.replace(new RegExp(`\\b${name}\\b`, "g"), name + suffix),
+    type
+  );
+
+/**
+ * Replace the references to the `both` types of a signature
+ * (`(req: X): Promise<Y>`), its parameters by the `Input` variants and its
+ * result by the `Output` ones
+ *
+ * @param {string} signature
+ * @param {string[]} names types to replace
+ * @return {string}
+ */
+function useSignatureVariants(signature, names) {
+  let depth = 0;
+  for (let i = 0; i < signature.length; i++) {
+    if (signature[i] === "(") depth++;
+    if (signature[i] === ")" && --depth === 0) {
+      return (
+        useVariant(signature.slice(0, i + 1), names, "Input") +
+        useVariant(signature.slice(i + 1), names, "Output")
+      );
+    }
+  }
+  return useVariant(signature, names, "Output");
+}
+
+/**
+ * Split the interfaces by direction.
+ *
+ * A type decoded from a request (`input`) tolerates missing fields, so all its
+ * fields are optional (except the ones marked `//go2dts:required`). A type
+ * encoded as a response (`output`) is emitted as is. A type used in both
+ * directions is emitted twice (`XInput` and `XOutput`).
+ *
+ * Directions come from (by priority) the `//go2dts:direction` directive,
+ * `directions.types`, the naming pattern (`directions.naming`) and
+ * `directions.packages`, and are propagated to the referenced types.
+ *
+ * @param {object[]} declarations
+ * @param {object} options `directions` configuration
+ * @return {object[]} declarations
+ */
+const applyDirections = (declarations, options = {}) => {
+  const naming =
+    options.naming === true
+      ? defaultNaming
+      : Object.assign({}, options.naming ? defaultNaming : {}, options.naming);
+  const types = options.types || {};
+  const packages = options.packages || {};
+
+  const interfaces = declarations.filter(d => d.kind === "interface");
+  const byName = {};
+  interfaces.forEach(d => (byName[d.name] = d));
+
+  // Explicit directions
+  const directions = {};
+  interfaces.forEach(d => {
+    const path = `${d.pkg}.${d.goName}`;
+    directions[d.name] =
+      d.direction ||
+      types[path] ||
+      ["input", "output"].find(
+        direction =>
+          naming[direction] && new RegExp(naming[direction]).test(d.goName)
+      ) ||
+      packages[d.pkg];
+  });
+
+  // Propagation to the referenced types
+  const references = d =>
+    Object.keys(byName).filter(name =>
+      d.fields
+        .map(f => f.type)
+        .concat(d.parent || "")
+        .some(type => new RegExp(`\\b${name}\\b`).test(type))
+    );
+  const propagate = (name, direction) => {
+    if (merge(directions[name], direction) === directions[name]) return;
+    directions[name] = merge(directions[name], direction);
+    references(byName[name]).forEach(i => propagate(i, directions[name]));
+  };
+  interfaces.forEach(d => {
+    if (directions[d.name]) {
+      references(d).forEach(i => propagate(i, directions[d.name]));
+    }
+  });
+
+  const both = Object.keys(directions).filter(i => directions[i] === "both");
+
+  const variant = (declaration, direction) => {
+    const isInput = direction === "input";
+    const suffix = isInput ? "Input" : "Output";
+    const rename = type => useVariant(type, both, suffix);
+    return Object.assign({}, declaration, {
+      name: rename(declaration.name),
+      parent: declaration.parent && rename(declaration.parent),
+      fields: declaration.fields.map(field =>
+        Object.assign({}, field, {
+          type: rename(field.type),
+          optional: field.optional || (isInput && !field.required)
+        })
+      )
+    });
+  };
+
+  // Methods take the `Input` variants and return the `Output` ones
+  const signatures = (declaration, variants) =>
+    Object.assign({}, declaration, {
+      fields: declaration.fields.map(field =>
+        Object.assign({}, field, { type: variants(field.type) })
+      )
+    });
+
+  // Types without direction reference the `Output` variants
+  return declarations
+    .map(declaration => {
+      if (declaration.kind === "alias") {
+        const type = useVariant(declaration.type, both, "Output");
+        return Object.assign({}, declaration, { type });
+      }
+      if (["service", "client"].includes(declaration.kind)) {
+        return signatures(declaration, type =>
+          useSignatureVariants(type, both)
+        );
+      }
+      if (declaration.kind === "routes") {
+        return signatures(declaration, type =>
+          type.replace(
+            /^\{ request: (.*); response: (.*) \}$/,
+            (match, request, response) =>
+              `{ request: ${useVariant(request, both, "Input")}; ` +
+              `response: ${useVariant(response, both, "Output")} }`
+          )
+        );
+      }
+      if (declaration.kind !== "interface") return declaration;
+
+      const direction = directions[declaration.name] || "output";
+      if (direction === "both") {
+        return [variant(declaration, "input"), variant(declaration, "output")];
+      }
+      return variant(declaration, direction);
+    })
+    .reduce((mem, i) => mem.concat(i), []);
+};
+
+module.exports = applyDirections;
@@ -0,0 +1,89 @@
+const { brand } = require("./brands");
+
+/**
+ * Typescript type of an interface field (with its nullability)
+ *
+ * @param {object} field
+ * @return {string}
+ */
+function fieldType(field) {
+  const nullable = field.nullable && !/\bnull\b|^any$/.test(field.type);
+  return `${field.type}${nullable ? " | null" : ""}`;
+}
+
+/**
+ * Emit the typescript declaration
+ *
+ * @param {object} declaration
+ * @param {object} options
+ * @param {function} options.isBranded (name) => boolean
+ * @return {string}
+ */
+function emitDeclaration(declaration, { isBranded }) {
+  const { name } = declaration;
+  switch (declaration.kind) {
+    case "scalar":
+      return `export type ${name} = ${
+        isBranded(name) ? brand(name, declaration.type) : declaration.type
+      }`;
+    case "enum":
+      const values = declaration.values.map(i => JSON.stringify(i));
+      return `export type ${name} = ${values.join(" | ") || "never"}`;
+    case "alias":
+      return `export type ${name} = ${declaration.type}`;
+    case "service":
+      const methods = declaration.fields.map(
+        d => `${d.http ? `  /** \`${d.http}\` */\n` : ""}  ${d.name}${d.type}`
+      );
+      return (
+        `export interface ${name} ` +
+        `${declaration.parent ? `extends ${declaration.parent} ` : ""}{\n` +
+        `${methods.join("\n")}\n}`
+      );
+    case "routes":
+      const routes = declaration.fields.map(
+        d => `  ${JSON.stringify(d.name)}: ${d.type}`
+      );
+      return `export interface ${name} {\n${routes.join("\n")}\n}`;
+    case "interface":
+      const { parent, fields, typeParameters } = declaration;
+      const generic = typeParameters ? `<${typeParameters}>` : "";
+      const body = fields
+        .filter(d => !d.oneof)
+        .map(d => `  ${d.name}${d.optional ? "?" : ""}: ${fieldType(d)}`)
+        .join("\n");
+      const oneofs = fields
+        .map(d => d.oneof)
+        .filter((oneof, i, oneofs) => oneof && oneofs.indexOf(oneof) === i);
+      if (!oneofs.length) {
+        return (
+          `export interface ${name}${generic} ` +
+          `${parent ? `extends ${parent} ` : ""}{\n` +
+          body +
+          "\n}"
+        );
+      }
+
+      // The oneofs members are mutually exclusive, and all absent when unset
+      const unions = oneofs.map(oneof => {
+        const members = fields.filter(d => d.oneof === oneof);
+        const variants = members
+          .map(member =>
+            [`${member.name}: ${fieldType(member)}`]
+              .concat(
+                members.filter(i => i !== member).map(i => `${i.name}?: never`)
+              )
+              .join("; ")
+          )
+          .concat(members.map(i => `${i.name}?: never`).join("; "));
+        return `(\n${variants.map(i => `  | { ${i} }`).join("\n")}\n)`;
+      });
+      return (
+        `export type ${name}${generic} = ${parent ? `${parent} & ` : ""}{\n` +
+        body +
+        `\n} & ${unions.join(" & ")}`
+      );
+  }
+}
+
+module.exports = { emitDeclaration, fieldType };
@@ -0,0 +1,23 @@
+/**
+ * Emit the const objects of the protobuf enums
+ * (`Status.ACTIVE` instead of `"ACTIVE"`)
+ *
+ * @param {object[]} enums enum declarations with `members`
+ * @return {string} typescript module
+ */
+const emitEnumObjects = enums =>
+  `// Generated by go2dts
+` +
+  enums
+    .map(
+      ({ name, members }) => `
+export const ${name} = {
+${members
+        .map(({ key, value }) => `  ${key}: ${JSON.stringify(value)}`)
+        .join(",\n")}
+} as const;
+`
+    )
+    .join("");
+
+module.exports = emitEnumObjects;
@@ -0,0 +1,113 @@
+// Default type argument of `ApiError` (the request type of the route)
+const defaultRequest = "{[key: string]: unknown}";
+
+/**
+ * Extract the error envelopes of a go file: the structs written by the error
+ * writers (`JSONError(w, message, code)` writing a `jsonErrorMessage{}`)
+ *
+ * @param {string} data go source
+ * @param {string[]} writers names of the error writers functions
+ * @param {object} typeKinds `pkg.GoName` -> kind (see `parseTypeKinds`)
+ * @return {string[]} `pkg.GoName` of the envelopes
+ */
+function parseErrorEnvelopes(data, writers, typeKinds) {
+  const [, pkg = ""] = /^package (\w+)/m.exec(data) || [];
+  const envelopes = [];
+  const funcRegex = /^func (\w+)\([^]*?{\n([^]*?)\n}/gm;
+  let m;
+  while ((m = funcRegex.exec(data)) !== null) {
+    if (!writers.includes(m[1])) continue;
+    const literalRegex = /\b(\w+){/g;
+    let literal;
+    while ((literal = literalRegex.exec(m[2])) !== null) {
+      if (typeKinds[`${pkg}.${literal[1]}`] !== "struct") continue;
+      envelopes.push(`${pkg}.${literal[1]}`);
+      break;
+    }
+  }
+  return envelopes;
+}
+
+/**
+ * The error envelope declaration, named `ApiError` and parameterized by the
+ * request type: its maps are keyed by the request properties
+ * (`fieldErrors: Partial<Record<keyof T & string, string[]>>`). It is flagged
+ * as `apiError`, its name can still be changed by the naming and the overrides.
+ *
+ * @param {object} declaration interface declaration of the envelope
+ * @return {object} declaration
+ */
+const apiErrorDeclaration = declaration =>
+  Object.assign({}, declaration, {
+    name: "ApiError",
+    apiError: true,
+    typeParameters: `T = ${defaultRequest}`,
+    fields: declaration.fields.map(field => {
+      const [, value] = /^\{\[key: string\]: (.*)\}$/.exec(field.type) || [];
+      return value
+        ? Object.assign({}, field, {
+            type: `Partial<Record<keyof T & string, ${value}>>`
+          })
+        : field;
+    })
+  });
+
+/**
+ * Runtime check of a field of the envelope
+ *
+ * @param {object} field
+ * @param {string} name name of the envelope
+ * @return {string|undefined}
+ */
+function fieldCheck(field, name) {
+  if (field.optional || field.nullable) return;
+  const access = `(value as ${name}).${field.name}`;
+  if (/\[\]$/.test(field.type)) return `Array.isArray(${access})`;
+  if (/^(\{|Partial<|Record<)/.test(field.type)) {
+    return `typeof ${access} === "object"`;
+  }
+  if (/^(string|number|boolean)$/.test(field.type)) {
+    return `typeof ${access} === "${field.type}"`;
+  }
+}
+
+/**
+ * Emit the `ApiError` guard (`isApiError`) and parser (`parseApiError`)
+ *
+ * @param {object} declaration `ApiError` declaration
+ * @param {string} typesModule path of the module declaring the types
+ * @return {string} typescript module
+ */
+const emitApiErrorHelpers = (declaration, typesModule) =>
+  `// Generated by go2dts
+
+import { ${declaration.name} } from "${typesModule}";
+
+export const isApiError = <T = ${defaultRequest}>(
+  value: unknown
+): value is ${declaration.name}<T> =>
+  ${['typeof value === "object"', "value !== null"]
+    .concat(
+      declaration.fields
+        .map(field => fieldCheck(field, declaration.name))
+        .filter(Boolean)
+    )
+    .join(" &&\n  ")};
+
+export const parseApiError = async <T = ${defaultRequest}>(
+  response: Response
+): Promise<${declaration.name}<T> | undefined> => {
+  if (response.ok) return undefined;
+  const body = await response
+    .clone()
+    .json()
+    .catch(() => undefined);
+  return isApiError<T>(body) ? body : undefined;
+};
+`;
+
+module.exports = {
+  apiErrorDeclaration,
+  emitApiErrorHelpers,
+  parseErrorEnvelopes
+};
@@ -0,0 +1,138 @@
+/**
+ * Extract the HTTP rules of the grpc-gateway handlers (`.pb.gw.go` files)
+ *
+ * @param {string} data go source
+ * @return {object} `Service.Method` -> `GET /v1/users/{id}`
+ */
+function parseGatewayRules(data) {
+  const rules = {};
+  const handlers = data.split(/(?=\bmux\.Handle\()/);
+  handlers.forEach(handler => {
+    const route = /^mux\.Handle\((?:"(\w+)"|http\.Method(\w+)), pattern_(\w+?)_(\w+)_\d+\b/.exec(
+      handler
+    );
+    if (!route) return;
+    const [, verb, method, service, rpc] = route;
+    const [, path] = /WithHTTPPathPattern\("([^"]*)"\)/.exec(handler) || [];
+    if (!path) return;
+    rules[`${service}.${rpc}`] = `${(verb || method).toUpperCase()} ${path}`;
+  });
+  return rules;
+}
+
+/**
+ * Extract the gRPC services of a go file, from the generated `XxxServer`
+ * interfaces.
+ *
+ * Services are `{ kind: "service", name, goName, pkg, fields }`, a field
+ * being a method (`type` is its signature):
+ *  - unary: `getUser(req: GetUserRequest): Promise<User>`
+ *  - server streaming: `listUsers(req: ListUsersRequest): AsyncIterable<User>`
+ *  - client streaming: `upload(req: AsyncIterable<Chunk>): Promise<Upload>`
+ *  - bidirectional streaming:
+ *    `chat(req: AsyncIterable<Msg>): AsyncIterable<Msg>`
+ *
+ * @param {string} data go source
+ * @param {object} options
+ * @param {string} options.pkg go package
+ * @param {function} options.resolveReference go type -> typescript type
+ * @param {object} options.httpRules `Service.Method` -> HTTP rule (see
+ * `parseGatewayRules`)
+ * @return {object[]} declarations
+ */
+function parseServices(data, { pkg, resolveReference, httpRules = {} }) {
+  const services = [];
+  const interfaces = {};
+  const interfaceRegex = /^type (\w+) interface {([^}]*)}/gm;
+  let m;
+  while ((m = interfaceRegex.exec(data)) !== null) interfaces[m[1]] = m[2];
+
+  // Streams: `grpc.ServerStreamingServer[Resp]` (or its alias), or the
+  // interfaces with `Send`/`SendAndClose` and `Recv` methods
+  const streamOf = name => {
+    const alias = new RegExp(`^type ${name} = (.*)$`, "m").exec(data);
+    const type = alias ? alias[1].trim() : name;
+    const generic = /^grpc\.(\w+)Server\[([\w.]+)(?:, ([\w.]+))?\]$/.exec(type);
+    if (generic) {
+      const [, kind, first, second] = generic;
+      if (kind === "ServerStreaming") return { response: first };
+      return {
+        request: first,
+        response: second,
+        clientStreaming: true,
+        bidi: kind === "BidiStreaming"
+      };
+    }
+    const body = interfaces[type] || "";
+    const [, send] = /\bSend\(\*?([\w.]+)\) error/.exec(body) || [];
+    const [, close] = /\bSendAndClose\(\*?([\w.]+)\) error/.exec(body) || [];
+    const [, recv] = /\bRecv\(\) \(\*?([\w.]+), error\)/.exec(body) || [];
+    return {
+      request: recv,
+      response: close || send,
+      clientStreaming: Boolean(recv),
+      bidi: Boolean(send)
+    };
+  };
+
+  // Generated servers only: an unnamed `context.Context` first parameter, or
+  // the `mustEmbedUnimplementedXxxServer` method
+  const generated = body =>
+    /^\s*[A-Z]\w*\(context\.Context,/m.test(body) ||
+    /^\s*mustEmbedUnimplemented\w*Server\(\)/m.test(body);
+
+  Object.keys(interfaces)
+    .filter(name => /^\w+Server$/.test(name) && !/_\w+Server$/.test(name))
+    .filter(name => generated(interfaces[name]))
+    .forEach(goName => {
+      const service = goName.replace(/Server$/, "");
+      const fields = [];
+      interfaces[goName].split("\n").forEach(line => {
+        const [, method, params, results] =
+          /^\s*([A-Z]\w*)\(([^)]*)\) (.*)$/.exec(line) || [];
+        if (!method) return;
+        const args = params.split(",").map(i => i.trim().replace(/^\*/, ""));
+        // Named parameters aren't generated
+        if (args.some(i => !/^[\w.]+$/.test(i))) return;
+        const ref = type => resolveReference(type.replace(/^\*/, ""));
+
+        let signature;
+        const unary = /^\(\*?([\w.]+), error\)$/.exec(results.trim());
+        if (unary && args[0] === "context.Context") {
+          signature = `(req: ${ref(args[1])}): Promise<${ref(unary[1])}>`;
+        } else if (args.length === 2) {
+          const { response } = streamOf(args[1]);
+          if (!response) return;
+          signature = `(req: ${ref(args[0])}): AsyncIterable<${ref(response)}>`;
+        } else if (args.length === 1) {
+          const { request, response, bidi } = streamOf(args[0]);
+          if (!request || !response) return;
+          const result = bidi
+            ? `AsyncIterable<${ref(response)}>`
+            : `Promise<${ref(response)}>`;
+          signature = `(req: AsyncIterable<${ref(request)}>): ${result}`;
+        }
+        if (!signature) return;
+
+        fields.push({
+          name: method.charAt(0).toLowerCase() + method.slice(1),
+          type: signature,
+          http: httpRules[`${service}.${method}`],
+          optional: false,
+          required: false,
+          nullable: false
+        });
+      });
+      if (fields.length === 0) return;
+      services.push({
+        kind: "service",
+        name: service,
+        goName: service,
+        pkg,
+        fields
+      });
+    });
+  return services;
+}
+
+module.exports = { parseGatewayRules, parseServices };
@@ -1,155 +1,421 @@
 const { readFileSync, readdirSync, writeFileSync } = require("fs");
-const mkdirp = require("mkdirp");
-const { join } = require("path");
-const { pascal } = require("case");
 const chalk = require("chalk");
+const mkdirp = require("mkdirp");
+const { basename, join } = require("path");
+const {
+  parseFile,
+  parseImports,
+  parseResponseTypes,
+  parseTypeDirectives,
+  parseTypeKinds
+} = require("./parser");
+const applyOverrides = require("./overrides");
+const applyNaming = require("./naming");
+const applyUnexported = require("./unexported");
+const applyDirections = require("./directions");
+const emitInt64Reviver = require("./reviver");
+const emitEnumObjects = require("./enums");
+const { usesProtojson, wellKnownType } = require("./protobuf");
+const { parseProto, protoPackage } = require("./proto");
+const { parseGatewayRules } = require("./grpc");
+const { emitClients } = require("./client");
+const { parseRoutes } = require("./routes");
+const {
+  apiErrorDeclaration,
+  emitApiErrorHelpers,
+  parseErrorEnvelopes
+} = require("./errors");
+const { brand, emitBrandHelpers } = require("./brands");
+const { emitDeclaration } = require("./emit");
+const {
+  decodableTypes,
+  decodedDeclaration,
+  emitDecoders
+} = require("./decoders");
+
+const goToTsMap = {
+  "sql.JSONStringArray": "string[]",
+  "sql.JSONStringMap": "{[key: string]: string}",
+  "sql.JSONMap": "any",
+  "null.Time": "Time | null",
+  "time.Time": "Time",
+  "timestamp.Timestamp": "Timestamp",
+  "uuid.UUID": "UUID",
+  "null.UUID": "UUID | null",
+  int: "number",
+  int8: "number",
+  int16: "number",
+  int32: "number",
+  int64: "number",
+  uint: "number",
+  uint8: "number",
+  uint16: "number",
+  uint32: "number",
+  byte: "number",
+  rune: "number",
+  float32: "number",
+  float64: "number",
+  bool: "boolean"
+};
+
+// Scalars aliases, declared only when referenced
+const builtinScalars = {
+  Time: "string",
+  Timestamp: "number",
+  UUID: "string",
+  Int64: "string"
+};
 
-const go2dts = (srcFolders, outFile) => {
-  let output = "";
+// Type of the 64-bit integers, by `int64` policy
+const int64Types = {
+  number: "number",
+  string: "Int64",
+  bigint: "bigint"
+};
+
+const go2dts = (srcFolders, outFile, config = {}) => {
+  // 64-bit integers policy (`number`, `string` or `bigint`)
+  const int64 = config.int64 || "number";
+  if (!int64Types[int64]) throw new Error(`Unknown int64 policy "${int64}"`);
+  const int64String = int64 === "number" ? "string" : int64Types[int64];
+
+  // User type mappings: a typescript type, or `{ type, from, import }`
+  Object.keys(config.types || {}).forEach(goType => {
+    const mapping = config.types[goType];
+    if (typeof mapping === "string") return;
+    if (!mapping || typeof mapping.type !== "string") {
+      throw new Error(`Invalid mapping of "${goType}": "type" is required`);
+    }
+    if (mapping.from && !mapping.import && !/^\w+/.test(mapping.type)) {
+      throw new Error(
+        `Invalid mapping of "${goType}": "import" is required with "from"`
+      );
+    }
+  });
 
-  // Flags to inject string aliases
-  let haveUUID = false;
-  let haveTime = false;
-  let haveTimestamp = false;
+  // Built-in mapping, extended by the user mapping (`config.types`)
+  const typeMap = Object.assign(
+    {},
+    goToTsMap,
+    { int64: int64Types[int64], uint64: int64Types[int64] },
+    config.types
+  );
+
+  // Typescript imports required by the user mapping (module -> names)
+  const tsImports = {};
 
+  const files = [];
+  const protoFiles = [];
   srcFolders.forEach(srcFolder =>
-    readdirSync(srcFolder)
-      .filter(fileName => /^[a-zA-Z-_]+(?!test)(.pb)?\.go$/.test(fileName))
-      .forEach(fileName => {
-        const data = readFileSync(join(srcFolder, fileName), "utf-8").replace(
-          /struct\{\}/g, // remove the type `struct{}` to simplify the parsing
-          "struct"
+    readdirSync(srcFolder).forEach(fileName => {
+      if (/^[a-zA-Z-_]+(?!test)(.pb(.gw)?)?\.go$/.test(fileName)) {
+        files.push(
+          readFileSync(join(srcFolder, fileName), "utf-8").replace(
+            /struct\{\}/g, // remove the type `struct{}` to simplify the parsing
+            "struct"
+          )
         );
-        let isEmpty = true;
-
-        // Extract const
-        const constRegex = /const \(([a-zA-Z\/ =,"\-\n\t\.()]*)\)/gm;
-        let n;
-        while ((n = constRegex.exec(data)) !== null) {
-          output +=
-            n[1]
-              .split("\n")
-              .filter(i => i.trim() !== "" && !i.startsWith("\t// "))
-              .reduce((mem, line, i) => {
-                try {
-                  const haveType = line.split(" =")[0].split(" ").length > 1;
-                  const type = line.split(" =")[0].split(" ")[1];
-                  const previousType = i > 0 ? mem[mem.length - 1].type : "";
-                  if (haveType && type !== previousType) {
-                    mem.push({
-                      type,
-                      values: [line.split(`"`)[1]]
-                    });
-                  } else {
-                    try {
-                      mem[mem.length - 1].values.push(line.split(`"`)[1]);
-                      isEmpty = false;
-                    } catch (e) {
-                      // functional error if the first line `const` don't have any type
-                      // we don't want to export this kind values for now
-                      // (it's not an enum pattern)
-                    }
-                  }
-                } catch (e) {
-                  console.log(
-                    `${chalk.yellow("Warning:")} ${n[1]} can't be imported`
-                  );
-                }
-                return mem;
-              }, [])
-              .map(i => `export type ${i.type} = "${i.values.join(`" | "`)}"`)
-              .join("\n\n") + "\n\n";
-        }
-
-        // Extract struct
-        const structRegex = /type\ (\w*)\ struct\ {([a-zA-Z0-9=\(\) .,-`"':\n\t\[\]\*]*)}/gm;
-        let m;
-        while ((m = structRegex.exec(data)) !== null) {
-          const name = m[1];
-          const details = m[2]
-            .split("\n")
-            .filter(i => i.trim() !== "" && i.includes("json"))
-            .map(parseParameter);
-
-          const parent = m[2].split("\n").filter(i => i.trim() !== "")[0] || ""
-          const haveParent = Boolean(parent.trim().match(/^[A-Z][a-zA-Z]+$/));
-
-          if (details.length === 0) {
-            continue;
-          } else {
-            isEmpty = false;
-            const values = details.map(({ type }) => type).join(",");
-            haveTime = haveTime || values.includes("Time");
-            haveUUID = haveUUID || values.includes("UUID");
-            haveTimestamp = haveTimestamp || values.includes("Timestamp");
-          }
-
-          output += `export interface ${pascal(name)} ${haveParent ? `extends ${parent.trim()} ` : ""}{`;
-          output +=
-            "\n" +
-            details
-              .filter(d => !d.internal)
-              .map(d => `  ${d.name}${d.optional ? "?" : ""}: ${d.type}`)
-              .join("\n");
-          output += "\n}\n\n";
-        }
+      }
+      if (/\.proto$/.test(fileName)) {
+        protoFiles.push(readFileSync(join(srcFolder, fileName), "utf-8"));
+      }
+    })
+  );
+
+  // `//go2dts:` directives of every type declaration (`pkg.GoName` ->
+  // directives)
+  const typeDirectives = files
+    .map(parseTypeDirectives)
+    .reduce((mem, directives) => Object.assign(mem, directives), {});
+
+  // Kind of every type declaration (`pkg.GoName` -> kind)
+  const typeKinds = files
+    .map(parseTypeKinds)
+    .reduce((mem, kinds) => Object.assign(mem, kinds), {});
+  const strict = config.nullability === "strict";
+
+  // HTTP rules of the gRPC methods (`Service.Method` -> rule)
+  const httpRules = files
+    .map(parseGatewayRules)
+    .reduce((mem, rules) => Object.assign(mem, rules), {});
+
+  // Longest fixed-size array emitted as a tuple (`[number, number]`)
+  const maxTupleLength =
+    config.maxTupleLength === undefined ? 8 : config.maxTupleLength;
+  const nullableElements = Boolean(config.nullableElements);
+
+  // Packages marshalled with the proto3 JSON mapping (`config.protobuf`
+  // forces `protojson` or `json` for all the packages, or by package)
+  const protojsonPackages = files
+    .filter(data => usesProtojson(parseImports(data)))
+    .map(data => (/^package (\w+)/m.exec(data) || [])[1])
+    .concat(protoFiles.map(protoPackage));
+  const isProtojson = pkg => {
+    const serializer =
+      typeof config.protobuf === "object"
+        ? config.protobuf[pkg]
+        : config.protobuf;
+    return serializer
+      ? serializer === "protojson"
+      : protojsonPackages.includes(pkg);
+  };
+
+  const resolveType = (type, goImports, pkg) => {
+    const [, goPkg, name] = /^(\w+)\.(\w+)$/.exec(type) || [];
+    const qualified = goImports[goPkg] && `${goImports[goPkg]}.${name}`;
+    const userTypes = config.types || {};
+    const mapping =
+      userTypes[qualified] ||
+      userTypes[type] ||
+      (goPkg &&
+        wellKnownType(goImports[goPkg] || goPkg, name, {
+          protojson: isProtojson(pkg),
+          int64String,
+          int64Type: int64Types[int64]
+        })) ||
+      typeMap[qualified] ||
+      typeMap[type];
+
+    if (!mapping) return type;
+    if (typeof mapping === "string") return mapping;
+    if (mapping.from) {
+      const names = tsImports[mapping.from] || [];
+      []
+        .concat(mapping.import || /^\w+/.exec(mapping.type)[0])
+        .filter(i => !names.includes(i))
+        .forEach(i => names.push(i));
+      tsImports[mapping.from] = names;
+    }
+    return mapping.type;
+  };
+
+  const parsed = files
+    .map(data =>
+      parseFile(data, {
+        resolveType,
+        typeDirectives,
+        typeKinds,
+        strict,
+        int64String,
+        maxTupleLength,
+        nullableElements,
+        enumsAsInts: Boolean(config.enumsAsInts),
+        isProtojson,
+        httpRules,
+        interfaces: Boolean(config.interfaces)
       })
+    )
+    .concat(
+      protoFiles.map(data =>
+        parseProto(data, {
+          resolveType,
+          int64String,
+          enumsAsInts: Boolean(config.enumsAsInts)
+        })
+      )
+    )
+    .concat(
+      config.routes ? parseRoutes(files, { resolveType, typeKinds }) : []
+    )
+    .reduce((mem, i) => mem.concat(i), []);
+
+  // Error envelopes, written by the error writers (`config.errorWriters`)
+  const errorWriters = config.errorWriters || [];
+  const envelopes = files
+    .map(data => parseErrorEnvelopes(data, errorWriters, typeKinds))
+    .reduce((mem, i) => mem.concat(i), []);
+  if (errorWriters.length && !envelopes.length) {
+    console.log(
+      `${chalk.yellow("Warning:")} no error envelope written by ${errorWriters
+        .map(i => `"${i}"`)
+        .join(", ")}`
+    );
+  }
+
+  // The named string types with const values are already emitted as enums
+  const enums = parsed.filter(d => d.kind === "enum").map(d => d.goName);
+
+  // Unexported types emitted anyway: forced by `//go2dts:export`, or written
+  // as JSON responses
+  const exportedTypes = parsed
+    .filter(d => (typeDirectives[`${d.pkg}.${d.goName}`] || {}).export)
+    .map(d => `${d.pkg}.${d.goName}`)
+    .concat(envelopes)
+    .concat(
+      config.unexported === "referenced"
+        ? files
+            .map(parseResponseTypes)
+            .reduce((mem, types) => mem.concat(types), [])
+        : []
+    );
+
+  // Scalars declared on top of the file, extended by `config.scalars`
+  const scalarTypes = Object.assign({}, builtinScalars, config.scalars);
+
+  const declarations = applyDirections(
+    applyNaming(
+      applyOverrides(
+        applyUnexported(
+          parsed
+            .filter(d => d.kind !== "scalar" || !enums.includes(d.goName))
+            .filter(d => d.kind !== "client" || config.client)
+            .map(d =>
+              d.kind === "interface" &&
+              envelopes.includes(`${d.pkg}.${d.goName}`)
+                ? apiErrorDeclaration(d)
+                : d
+            ),
+          config.unexported,
+          exportedTypes
+        ),
+        config.overrides
+      ),
+      config.naming,
+      Object.keys(scalarTypes)
+    ),
+    config.directions
   );
 
+  // Branded types (`true` for all the scalars, or list of type names)
+  const isBranded = name =>
+    config.branded === true ||
+    (Array.isArray(config.branded) && config.branded.includes(name));
+
+  // Scalars actually referenced by the emitted declarations
+  const referenced = declarations
+    .map(d =>
+      d.fields
+        ? d.fields.map(({ type }) => type).concat(d.parent || "")
+        : [d.type || ""]
+    )
+    .reduce((mem, types) => mem.concat(types.map(typeReferences)), [])
+    .reduce((mem, names) => mem.concat(names), []);
+  const scalars = Object.keys(scalarTypes)
+    .filter(name => referenced.includes(name))
+    .map(name => {
+      const type = scalarTypes[name];
+      const branded =
+        name === "Int64" || (type === "string" && isBranded(name));
+      return { name, type, branded };
+    });
+
+  // Shared scalars module (`config.scalarsFrom`)
+  if (config.scalarsFrom && scalars.length) {
+    const names = tsImports[config.scalarsFrom] || [];
+    tsImports[config.scalarsFrom] = names.concat(scalars.map(i => i.name));
+  }
+
+  // Interfaces with dates, decoded by the decoders module (`config.decoders`)
+  const decodable = config.decoders ? decodableTypes(declarations) : new Set();
+
+  let output = declarations
+    .filter(d => d.kind !== "client")
+    .map(d =>
+      [d]
+        .concat(decodable.has(d.name) ? decodedDeclaration(d, decodable) : [])
+        .map(i => emitDeclaration(i, { isBranded }) + "\n\n")
+        .join("")
+    )
+    .join("");
+
   let outputPrepend = "// Generated by go2dts\n\n";
 
-  if (haveTime) outputPrepend += "export type Time = string\n\n";
-  if (haveTimestamp) outputPrepend += "export type Timestamp = number\n\n";
-  if (haveUUID) outputPrepend += "export type UUID = string\n\n";
+  Object.keys(tsImports).forEach(from => {
+    outputPrepend += `import { ${tsImports[from].join(", ")} } from "${from}"\n`;
+  });
+  if (Object.keys(tsImports).length) outputPrepend += "\n";
+
+  if (!config.scalarsFrom) {
+    scalars.forEach(({ name, type, branded }) => {
+      outputPrepend += `export type ${name} = ${
+        branded ? brand(name, type) : type
+      }\n\n`;
+    });
+  }
 
   output = outputPrepend + output;
   mkdirp.sync(join(outFile, "../"));
   writeFileSync(outFile, output);
-};
 
-function parseArray(i) {
-  const isArray = /\[\]/.test(i);
-  return i.replace(/[\[\]\*]|types\./g, "") + (isArray ? "[]" : "");
-}
+  // `Int64` is always branded with the string policy, `config.branded` or not
+  const brandedTypes = (config.scalarsFrom ? [] : scalars)
+    .concat(declarations.filter(d => d.kind === "scalar"))
+    .filter(d => d.branded || (d.kind === "scalar" && isBranded(d.name)))
+    .map(d => d.name);
+  if (brandedTypes.length) {
+    writeFileSync(
+      outFile.replace(/(\.d)?\.ts$/, "") + ".brands.ts",
+      emitBrandHelpers(
+        brandedTypes,
+        `./${basename(outFile).replace(/(\.d)?\.ts$/, "")}`
+      )
+    );
+  }
+
+  if (decodable.size) {
+    writeFileSync(
+      outFile.replace(/(\.d)?\.ts$/, "") + ".decoders.ts",
+      emitDecoders(
+        declarations,
+        decodable,
+        `./${basename(outFile).replace(/(\.d)?\.ts$/, "")}`
+      )
+    );
+  }
+
+  const enumObjects = declarations.filter(d => d.kind === "enum" && d.members);
+  if (config.enumObjects && enumObjects.length) {
+    writeFileSync(
+      outFile.replace(/(\.d)?\.ts$/, "") + ".enums.ts",
+      emitEnumObjects(enumObjects)
+    );
+  }
+
+  const apiError = declarations.find(d => d.kind === "interface" && d.apiError);
+  if (apiError) {
+    writeFileSync(
+      outFile.replace(/(\.d)?\.ts$/, "") + ".errors.ts",
+      emitApiErrorHelpers(
+        apiError,
+        `./${basename(outFile).replace(/(\.d)?\.ts$/, "")}`
+      )
+    );
+  }
+
+  const clients = declarations.filter(d => d.kind === "client");
+  if (clients.length) {
+    writeFileSync(
+      outFile.replace(/(\.d)?\.ts$/, "") + ".client.ts",
+      emitClients(
+        clients,
+        declarations
+          .filter(d => d.kind !== "client")
+          .map(d => d.name)
+          .concat(config.scalarsFrom ? [] : scalars.map(i => i.name)),
+        `./${basename(outFile).replace(/(\.d)?\.ts$/, "")}`,
+        apiError && apiError.name
+      )
+    );
+  }
 
-function parseParameter(i) {
-  if (i.includes(`json:"-"`)) return { internal: true };
-
-  try {
-    const [
-      ,
-      t,
-      name
-    ] = /\t\w* *([a-zA-Z_0-9.*\[\]]+) *`[a-zA-Z:",0-9= -]*json:"(\w+)/.exec(i);
-    const goToTsMap = {
-      "sql.JSONStringArray": "string[]",
-      "sql.JSONStringMap": "{[key: string]: string}",
-      "sql.JSONMap": "any",
-      "null.Time": "Time | null",
-      "time.Time": "Time",
-      "timestamp.Timestamp": "Timestamp",
-      "uuid.UUID": "UUID",
-      "null.UUID": "UUID | null",
-      int: "number",
-      int32: "number",
-      uint32: "number",
-      int64: "number",
-      bool: "boolean"
-    };
-
-    const optional = /\*/.test(t) || i.includes("omitempty");
-    const isMap = /map\[(\w*)\](.*)/.exec(t);
-
-    let type = isMap
-      ? `{[key: ${isMap[1]}]: ${parseArray(isMap[2])}}`
-      : parseArray(t);
-
-    if (goToTsMap[type]) type = goToTsMap[type];
-    return { type, name, optional };
-  } catch (e) {
-    console.log(e);
-    console.log(`${i} can't be parsed`);
+  if (int64 === "bigint") {
+    writeFileSync(
+      outFile.replace(/(\.d)?\.ts$/, "") + ".reviver.ts",
+      emitInt64Reviver(declarations)
+    );
   }
+};
+
+/**
+ * Identifiers referenced by a typescript type
+ *
+ * @param {string} type
+ * @return {string[]}
+ */
+function typeReferences(type) {
+  return (
+    type.replace(/"[^"]*"|'[^']*'/g, "").match(/\b[A-Za-z_$][\w$]*\b/g) || []
+  );
 }
 
 module.exports = go2dts;
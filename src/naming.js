@@ -0,0 +1,144 @@
+const { pascal } = require("case");
+const chalk = require("chalk");
+
+// Naming conventions of the declarations (`capitalize` keeps the go
+// initialisms, `HTTPError`, where `pascal` gives `HttpError`)
+const conventions = {
+  capitalize: name => name.charAt(0).toUpperCase() + name.slice(1),
+  pascal,
+  preserve: name => name
+};
+
+// Typescript globals and keywords a declaration can't shadow (the emitted
+// types rely on `Record`, `Partial` and `Date`)
+const reservedNames = [
+  "Array",
+  "BigInt",
+  "Boolean",
+  "Date",
+  "Error",
+  "Function",
+  "JSON",
+  "Map",
+  "Number",
+  "Object",
+  "Omit",
+  "Partial",
+  "Pick",
+  "Promise",
+  "Readonly",
+  "Record",
+  "RegExp",
+  "Required",
+  "Set",
+  "String",
+  "Symbol",
+  "any",
+  "bigint",
+  "boolean",
+  "never",
+  "null",
+  "number",
+  "object",
+  "string",
+  "symbol",
+  "type",
+  "undefined",
+  "unknown",
+  "void"
+];
+
+/**
+ * Name the declarations following the `naming` configuration, and resolve all
+ * the references (`pkg.GoName`, see `parseFile`) to the declarations names.
+ *
+ * The names set explicitly (`//go2dts:name` directive or `overrides`) are
+ * kept as is. The names declared twice (same type name in two packages), or
+ * shadowing a typescript global (`Record`, `Date`...) or scalar, are prefixed
+ * by their package (`AuthConfig`), and reported.
+ *
+ * @param {object[]} declarations
+ * @param {object} options `naming` configuration
+ * @param {string} options.case `capitalize` (default), `pascal` or `preserve`
+ * @param {string} options.prefix
+ * @param {string} options.suffix
+ * @param {string[]} reserved additional reserved names (the scalars)
+ * @return {object[]} declarations
+ */
+const applyNaming = (declarations, options = {}, reserved = []) => {
+  const convention = conventions[options.case || "capitalize"];
+  if (!convention) throw new Error(`Unknown naming case "${options.case}"`);
+  const { prefix = "", suffix = "" } = options;
+  const isReserved = name =>
+    reservedNames.includes(name) || reserved.includes(name);
+
+  const key = d => `${d.pkg}.${d.goName}`;
+  const baseName = d =>
+    d.name === d.goName ? prefix + convention(d.goName) + suffix : d.name;
+
+  // Declarations names (`pkg.GoName` -> name)
+  const counts = {};
+  declarations.forEach(d => {
+    counts[baseName(d)] = (counts[baseName(d)] || 0) + 1;
+  });
+  const names = {};
+  declarations.forEach(d => {
+    const name = baseName(d);
+    if (counts[name] === 1 && !isReserved(name)) return (names[key(d)] = name);
+
+    names[key(d)] = d.pkg
+      ? prefix + pascal(d.pkg) + name.slice(prefix.length)
+      : name;
+    const reason =
+      counts[name] > 1
+        ? "declared in several packages"
+        : `shadows the typescript "${name}"`;
+    console.log(
+      `${chalk.yellow("Warning:")} "${key(d)}" renamed to "${
+        names[key(d)]
+      }" (${reason})`
+    );
+  });
+
+  // go name -> names of the declarations
+  const byGoName = {};
+  declarations.forEach(d => {
+    byGoName[d.goName] = (byGoName[d.goName] || []).concat(names[key(d)]);
+  });
+
+  /**
+   * Resolve the go references of a typescript type (the string literals are
+   * kept as is)
+   *
+   * @param {string} type typescript type
+   * @return {string}
+   */
+  const resolveReferences = type =>
+    type.replace(
+      /"[^"]*"|'[^']*'|\b(\w+)\.(\w+)\b/g,
+      (match, qualifier, goName) =>
+        qualifier && byGoName[goName]
+          ? names[match] || byGoName[goName][0]
+          : match
+    );
+
+  return declarations.map(declaration => {
+    const renamed = Object.assign({}, declaration, {
+      name: names[key(declaration)]
+    });
+    if (declaration.type) {
+      renamed.type = resolveReferences(declaration.type);
+    }
+    if (declaration.parent) {
+      renamed.parent = resolveReferences(declaration.parent);
+    }
+    if (declaration.fields) {
+      renamed.fields = declaration.fields.map(field =>
+        Object.assign({}, field, { type: resolveReferences(field.type) })
+      );
+    }
+    return renamed;
+  });
+};
+
+module.exports = applyNaming;
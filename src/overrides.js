@@ -0,0 +1,82 @@
+const chalk = require("chalk");
+
+/**
+ * Normalize an override value
+ *
+ * @param {string|object} override
+ * @return {object} `{ type, name, optional, skip }`
+ */
+const normalize = override =>
+  typeof override === "string" ? { type: override } : override;
+
+/**
+ * Apply the `overrides` configuration on the parsed declarations.
+ *
+ * Paths are `<package>.<Type>` or `<package>.<Type>.<jsonField>`.
+ *
+ * @param {object[]} declarations
+ * @param {object} overrides path -> override
+ * @return {object[]} declarations
+ */
+const applyOverrides = (declarations, overrides = {}) => {
+  const matched = new Set();
+
+  const result = declarations
+    .map(declaration => {
+      const path = `${declaration.pkg}.${declaration.goName}`;
+      if (!overrides[path]) return declaration;
+      matched.add(path);
+
+      const override = normalize(overrides[path]);
+      if (override.skip) return;
+
+      const name = override.name || declaration.name;
+      if (override.type) {
+        return Object.assign({}, declaration, {
+          kind: "alias",
+          name,
+          type: override.type
+        });
+      }
+      return Object.assign({}, declaration, {
+        name,
+        fields:
+          declaration.fields &&
+          declaration.fields.map(field =>
+            override.optional === undefined
+              ? field
+              : Object.assign({}, field, { optional: override.optional })
+          )
+      });
+    })
+    .filter(Boolean)
+    .map(declaration => {
+      if (declaration.kind !== "interface") return declaration;
+
+      const fields = declaration.fields
+        .map(field => {
+          const path = `${declaration.pkg}.${declaration.goName}.${field.name}`;
+          if (!overrides[path]) return field;
+          matched.add(path);
+
+          const override = normalize(overrides[path]);
+          if (override.skip) return;
+          return Object.assign({}, field, override);
+        })
+        .filter(Boolean);
+
+      return Object.assign({}, declaration, { fields });
+    });
+
+  Object.keys(overrides)
+    .filter(path => !matched.has(path))
+    .forEach(path =>
+      console.log(
+        `${chalk.yellow("Warning:")} override "${path}" doesn't match anything`
+      )
+    );
+
+  return result;
+};
+
+module.exports = applyOverrides;
@@ -0,0 +1,897 @@
+const chalk = require("chalk");
+const { parseClients } = require("./client");
+const { parseServices } = require("./grpc");
+
+// Kind of the external go types, as seen by `encoding/json`
+const goKinds = {
+  "sql.JSONStringArray": "slice",
+  "sql.JSONStringMap": "map",
+  "sql.JSONMap": "map",
+  "null.Time": "struct",
+  "time.Time": "struct",
+  "timestamp.Timestamp": "struct",
+  "uuid.UUID": "array",
+  "null.UUID": "struct"
+};
+
+/**
+ * Parse a go file
+ *
+ * Declarations are one of:
+ *  - `{ kind: "enum", name, goName, pkg, values, members }` (`members` are the
+ *    `{ key, value }` of the protobuf enums)
+ *  - `{ kind: "alias", name, goName, pkg, type }`
+ *  - `{ kind: "scalar", name, goName, pkg, type }` (named go string types)
+ *  - `{ kind: "interface", name, goName, pkg, direction, parent, fields }`
+ *    (the members of the protobuf oneofs are fields with a `oneof` name)
+ *  - `{ kind: "service", name, goName, pkg, parent, fields }` (see
+ *    `parseServices`, and the go interfaces with the `interfaces` option)
+ *  - `{ kind: "client", name, goName, pkg, options, base, fields }` (see
+ *    `parseClients`)
+ *
+ * @param {string} data go source
+ * @param {object} options
+ * @param {function} options.resolveType (goType, goImports, pkg) => typescript
+ * type
+ * @param {object} options.typeDirectives `pkg.GoName` -> directives
+ * @param {object} options.typeKinds `pkg.GoName` -> kind (see `parseTypeKinds`)
+ * @param {boolean} options.strict model the nullability of `encoding/json`
+ * @param {string} options.int64String type of the 64-bit integers encoded
+ * as strings
+ * @param {number} options.maxTupleLength longest fixed-size array emitted as
+ * a tuple
+ * @param {boolean} options.nullableElements emit the pointer elements of the
+ * slices, arrays and maps as nullable
+ * @param {boolean} options.enumsAsInts emit the protobuf enums as their
+ * numbers (protojson `EnumsAsInts`)
+ * @param {function} options.isProtojson (pkg) => the package is encoded with
+ * protojson
+ * @param {object} options.httpRules HTTP rules of the gRPC methods (see
+ * `parseGatewayRules`)
+ * @param {boolean} options.interfaces emit the exported go interfaces (the
+ * contexts are dropped, and the methods returning an error are async)
+ * @return {object[]} declarations
+ */
+function parseFile(data, options) {
+  const {
+    resolveType,
+    typeDirectives,
+    typeKinds,
+    strict,
+    int64String,
+    maxTupleLength,
+    nullableElements,
+    enumsAsInts,
+    isProtojson = () => false,
+    httpRules,
+    interfaces
+  } = options;
+  const declarations = [];
+  const [, pkg = ""] = /^package (\w+)/m.exec(data) || [];
+  const resolveReference = referenceResolver(data, { resolveType, typeKinds });
+
+  // Extract const
+  const constRegex = /const \(([a-zA-Z\/ =,"\-\n\t\.()]*)\)/gm;
+  let n;
+  while ((n = constRegex.exec(data)) !== null) {
+    n[1]
+      .split("\n")
+      .filter(i => i.trim() !== "" && !i.startsWith("\t// "))
+      .reduce((mem, line, i) => {
+        try {
+          const haveType = line.split(" =")[0].split(" ").length > 1;
+          const type = line.split(" =")[0].split(" ")[1];
+          const previousType = i > 0 ? mem[mem.length - 1].type : "";
+          if (haveType && type !== previousType) {
+            mem.push({
+              type,
+              values: [line.split(`"`)[1]]
+            });
+          } else {
+            try {
+              mem[mem.length - 1].values.push(line.split(`"`)[1]);
+            } catch (e) {
+              // functional error if the first line `const` don't have any type
+              // we don't want to export this kind values for now
+              // (it's not an enum pattern)
+            }
+          }
+        } catch (e) {
+          console.log(`${chalk.yellow("Warning:")} ${n[1]} can't be imported`);
+        }
+        return mem;
+      }, [])
+      .filter(i => !(typeDirectives[`${pkg}.${i.type}`] || {}).ignore)
+      .forEach(i => {
+        const directives = typeDirectives[`${pkg}.${i.type}`] || {};
+        const name = directives.name || i.type;
+        const goName = i.type;
+        declarations.push(
+          directives.type
+            ? { kind: "alias", name, goName, pkg, type: directives.type }
+            : { kind: "enum", name, goName, pkg, values: i.values }
+        );
+      });
+  }
+
+  // Extract the protobuf enums, from the generated `Status_name` maps
+  // (protojson encodes their names, `encoding/json` their numbers)
+  const protoEnumRegex = /^\s*(?:var\s+)?(\w+)_name\s*=\s*map\[int32\]string\s*{([^}]*)}/gm;
+  while ((n = protoEnumRegex.exec(data)) !== null) {
+    const goName = n[1];
+    const directives = typeDirectives[`${pkg}.${goName}`] || {};
+    const name = directives.name || goName;
+    if (directives.ignore) continue;
+    if (directives.type) {
+      const type = directives.type;
+      declarations.push({ kind: "alias", name, goName, pkg, type });
+      continue;
+    }
+
+    // Keys are the names without the `Status_` prefix of the const
+    const members = [];
+    const entryRegex = /(-?\d+)\s*:\s*"(\w+)"/g;
+    let entry;
+    while ((entry = entryRegex.exec(n[2])) !== null) {
+      const value =
+        enumsAsInts || !isProtojson(pkg) ? Number(entry[1]) : entry[2];
+      members.push({ key: entry[2], value });
+    }
+    const values = members.map(i => i.value);
+    declarations.push({ kind: "enum", name, goName, pkg, values, members });
+  }
+
+  // Extract named string types (the enums are already extracted from the const)
+  const scalarRegex = /^type (\w+) string[ \t]*(?:\/\/.*)?$/gm;
+  let m;
+  while ((m = scalarRegex.exec(data)) !== null) {
+    const goName = m[1];
+    const directives = typeDirectives[`${pkg}.${goName}`] || {};
+    const name = directives.name || goName;
+    if (directives.ignore) continue;
+
+    declarations.push(
+      directives.type
+        ? { kind: "alias", name, goName, pkg, type: directives.type }
+        : { kind: "scalar", name, goName, pkg, type: "string" }
+    );
+  }
+
+  // Extract struct
+  const structRegex = /type (\w*) struct {([^{}]*)}/gm;
+  while ((m = structRegex.exec(data)) !== null) {
+    const goName = m[1];
+    const directives = typeDirectives[`${pkg}.${goName}`] || {};
+    const name = directives.name || goName;
+    if (directives.ignore) continue;
+
+    if (directives.type) {
+      const type = directives.type;
+      declarations.push({ kind: "alias", name, goName, pkg, type });
+      continue;
+    }
+
+    const parameterOptions = directives => ({
+      directives,
+      kindOf: type => kindOf(type, typeKinds, pkg),
+      strict,
+      int64String,
+      maxTupleLength,
+      nullableElements,
+      protojson: isProtojson(pkg)
+    });
+
+    // Fields with their leading and trailing comments
+    let comments = [];
+    const details = [];
+    m[2].split("\n").forEach(line => {
+      if (/^\s*\/\//.test(line)) return comments.push(line);
+      const oneof = parseTags(line).protobuf_oneof;
+      if (oneof && !isProtojson(pkg)) {
+        // `encoding/json` encodes the oneof interface as its wrapper struct
+        // (`"Action": {"Start": {...}}`), `null` when unset
+        const [, fieldName, iface] = /^\s*(\w+)\s+(\w+)/.exec(line);
+        const wrappers = parseOneofWrappers(data, goName, iface);
+        const variants = wrappers.map(wrapper => {
+          const member = parseParameter(
+            wrapper,
+            resolveReference,
+            parameterOptions({})
+          );
+          const type = member.nullable ? `${member.type} | null` : member.type;
+          return `{${member.name}${member.optional ? "?" : ""}: ${type}}`;
+        });
+        if (variants.length) {
+          details.push({
+            name: fieldName,
+            type: variants.join(" | "),
+            optional: !strict,
+            required: false,
+            nullable: strict
+          });
+        }
+      } else if (oneof) {
+        const [, iface] = /^\s*\w+\s+(\w+)/.exec(line);
+        parseOneofWrappers(data, goName, iface).forEach(wrapper => {
+          const protobuf = parseTags(wrapper).protobuf;
+          const directives = { name: protobufName(protobuf) };
+          const member = parseParameter(
+            wrapper,
+            resolveReference,
+            parameterOptions(directives)
+          );
+          details.push(
+            Object.assign({}, member, {
+              oneof,
+              optional: false,
+              nullable: false
+            })
+          );
+        });
+      } else if (
+        line.trim() !== "" &&
+        line.includes("json") &&
+        !/,oneof\b/.test(parseTags(line).protobuf || "")
+      ) {
+        const fieldDirectives = parseDirectives(comments.concat(line));
+        details.push(
+          parseParameter(
+            line,
+            resolveReference,
+            parameterOptions(fieldDirectives)
+          )
+        );
+      }
+      comments = [];
+    });
+
+    const parent =
+      m[2].split("\n").filter(i => i.trim() !== "" && !/^\s*\/\//.test(i))[0] ||
+      "";
+    const haveParent = Boolean(parent.trim().match(/^[A-Z][a-zA-Z]+$/));
+
+    if (details.length === 0) continue;
+
+    declarations.push({
+      kind: "interface",
+      name,
+      goName,
+      pkg,
+      direction: directives.direction,
+      parent: haveParent ? resolveReference(parent.trim()) : undefined,
+      fields: details
+        .filter(d => !d.internal)
+        .map(d =>
+          Object.assign({}, d, {
+            optional: d.optional || Boolean(directives.optional)
+          })
+        )
+    });
+  }
+
+  // Extract the gRPC services
+  const services = parseServices(data, { pkg, resolveReference, httpRules });
+  services.forEach(i => declarations.push(i));
+
+  // Extract the exported interfaces as their methods (the gRPC servers are
+  // already extracted as services, the clients and streams are skipped)
+  const interfaceRegex = /^type ([A-Z]\w*) interface {\n([^]*?)\n}/gm;
+  while (interfaces && (m = interfaceRegex.exec(data)) !== null) {
+    const goName = m[1];
+    const directives = typeDirectives[`${pkg}.${goName}`] || {};
+    const name = directives.name || goName;
+    const generated =
+      /_/.test(goName) ||
+      services.some(i =>
+        [`${i.goName}Server`, `${i.goName}Client`].includes(goName)
+      );
+    if (directives.ignore || generated) continue;
+
+    const tsType = type =>
+      toTsType(parseGoType(type), {
+        resolveType: resolveReference,
+        kindOf: type => kindOf(type, typeKinds, pkg),
+        maxTupleLength,
+        nullableElements
+      });
+    const parents = [];
+    const fields = [];
+    m[2].split("\n").forEach(line => {
+      const embedded = /^\s*([\w.]+)\s*(\/\/.*)?$/.exec(line);
+      if (embedded) {
+        const [, qualifier, embeddedName] =
+          /^(?:(\w+)\.)?(\w+)$/.exec(embedded[1]) || [];
+        const kind = typeKinds[`${pkg}.${embeddedName}`];
+        if (!qualifier && kind === "interface") {
+          parents.push(resolveReference(embeddedName));
+        }
+        return;
+      }
+      const method = parseMethod(line);
+      if (!method || !/^[A-Z]/.test(method.name)) return;
+
+      const params = method.params
+        .filter(i => i.type !== "context.Context")
+        .map((param, i) => {
+          const variadic = /^\.\.\./.test(param.type);
+          const type = tsType(param.type.replace(/^\.\.\./, ""));
+          return variadic
+            ? `...${param.name || `arg${i}`}: ${
+                / [|&] /.test(type) ? `(${type})` : type
+              }[]`
+            : `${param.name || `arg${i}`}: ${type}`;
+        });
+      const results = method.results.map(i => i.type);
+      const values = results.filter(i => i !== "error").map(tsType);
+      const value =
+        values.length > 1 ? `[${values.join(", ")}]` : values[0] || "void";
+      fields.push({
+        name: method.name.charAt(0).toLowerCase() + method.name.slice(1),
+        type: `(${params.join(", ")}): ${
+          results.includes("error") ? `Promise<${value}>` : value
+        }`,
+        optional: false,
+        required: false,
+        nullable: false
+      });
+    });
+    if (!fields.length && !parents.length) continue;
+
+    declarations.push({
+      kind: "service",
+      name,
+      goName,
+      pkg,
+      parent: parents.length ? parents.join(", ") : undefined,
+      fields
+    });
+  }
+
+  // Extract the HTTP clients
+  parseClients(data, { pkg, resolveReference }).forEach(i =>
+    declarations.push(i)
+  );
+
+  return declarations;
+}
+
+/**
+ * Split a go list (parameters, results) on its top-level commas
+ *
+ * @param {string} list
+ * @return {string[]}
+ */
+function splitList(list) {
+  const items = [];
+  let depth = 0;
+  let item = "";
+  for (const c of list) {
+    if ("([{".includes(c)) depth++;
+    if (")]}".includes(c)) depth--;
+    if (c === "," && depth === 0) {
+      items.push(item.trim());
+      item = "";
+    } else item += c;
+  }
+  if (item.trim()) items.push(item.trim());
+  return items;
+}
+
+/**
+ * Parameters, or results, of a go signature (`ctx context.Context, a, b int`
+ * or `*User, error`)
+ *
+ * @param {string} list
+ * @return {object[]} `{ name, type }`, `name` is undefined for the unnamed
+ */
+function parseSignatureList(list) {
+  const items = splitList(list);
+  const named = items.some(
+    i => /^\w+\s+\S/.test(i) && !/^(chan|func)\b/.test(i)
+  );
+  if (!named) return items.map(type => ({ type }));
+
+  // The names can share a type (`a, b int`)
+  let type;
+  return items
+    .reverse()
+    .map(item => {
+      const [, name, itemType] = /^(\w+)(?:\s+(.*))?$/.exec(item) || [];
+      type = itemType || type;
+      return { name, type };
+    })
+    .reverse();
+}
+
+/**
+ * Parse a method of a go interface (`Get(ctx context.Context, id string)
+ * (*User, error)`)
+ *
+ * @param {string} line
+ * @return {object|undefined} `{ name, params, results }`
+ */
+function parseMethod(line) {
+  const [, name, rest] =
+    /^\s*(\w+)\((.*)$/.exec(line.replace(/\/\/.*$/, "")) || [];
+  if (!name) return;
+
+  // The parameters end on the matching parenthesis
+  let depth = 1;
+  let end = 0;
+  for (; end < rest.length && depth > 0; end++) {
+    if (rest[end] === "(") depth++;
+    if (rest[end] === ")") depth--;
+  }
+  const results = rest
+    .slice(end)
+    .trim()
+    .replace(/^\((.*)\)$/, "$1");
+  return {
+    name,
+    params: parseSignatureList(rest.slice(0, end - 1)),
+    results: parseSignatureList(results)
+  };
+}
+
+/**
+ * Resolver of the type references of a go file.
+ *
+ * References to the declared types are qualified by their package, and
+ * resolved to the declarations names by the naming pass (see `applyNaming`).
+ *
+ * @param {string} data go source
+ * @param {object} options
+ * @param {function} options.resolveType (goType, goImports, pkg) => typescript
+ * type
+ * @param {object} options.typeKinds `pkg.GoName` -> kind (see `parseTypeKinds`)
+ * @return {function} go type -> typescript type
+ */
+function referenceResolver(data, { resolveType, typeKinds }) {
+  const [, pkg = ""] = /^package (\w+)/m.exec(data) || [];
+  const goImports = parseImports(data);
+  const unknownTypes = new Set();
+  return type => {
+    const resolved = resolveType(type, goImports, pkg);
+    const [, qualifier, goName] = /^(?:(\w+)\.)?(\w+)$/.exec(type) || [];
+    if (resolved !== type || !goName) return resolved;
+    const qualified = `${qualifier || pkg}.${goName}`;
+    if (typeKinds[qualified]) return qualified;
+    if (!qualifier) return goName;
+
+    // Types of the packages that aren't parsed (`http.Request`)
+    if (!unknownTypes.has(type)) {
+      unknownTypes.add(type);
+      console.log(
+        `${chalk.yellow("Warning:")} type "${type}" not found, typed as unknown`
+      );
+    }
+    return "unknown";
+  };
+}
+
+/**
+ * Extract the imports of a go file
+ *
+ * @param {string} data go source
+ * @return {object} package name -> import path
+ */
+function parseImports(data) {
+  const imports = {};
+  const importRegex = /^import\s+(?:\(([^)]*)\)|(.*))$/gm;
+  let m;
+  while ((m = importRegex.exec(data)) !== null) {
+    (m[1] || m[2]).split("\n").forEach(line => {
+      const spec = /^\s*([\w.]+\s+)?"([^"]+)"/.exec(line);
+      if (!spec) return;
+      const path = spec[2];
+      const name = spec[1]
+        ? spec[1].trim()
+        : path
+            .split("/")
+            .filter(i => !/^v\d+$/.test(i))
+            .pop()
+            .replace(/^go[.-]|[.-]go$|\.v\d+$/g, "");
+      imports[name] = path;
+    });
+  }
+  return imports;
+}
+
+/**
+ * Extract the member fields of a protobuf oneof
+ *
+ * The members are the wrappers listed by `XXX_OneofWrappers` (all the oneofs
+ * of the message) implementing the oneof interface, each wrapper holding a
+ * single field.
+ *
+ * @param {string} data go source
+ * @param {string} message go name of the message
+ * @param {string} iface go name of the oneof interface (`isMessage_Oneof`)
+ * @return {string[]} field declarations
+ */
+function parseOneofWrappers(data, message, iface) {
+  const list = new RegExp(
+    `func \\(\\*?${message}\\) XXX_OneofWrappers\\(\\).*\\n\\s*` +
+      `return \\[\\](?:interface{}|any){([^}]*)}`
+  ).exec(data);
+  const wrappers = ((list && list[1]) || "").match(/\(\*\w+\)\(nil\)/g) || [];
+  return wrappers
+    .map(i => /\(\*(\w+)\)/.exec(i)[1])
+    .filter(wrapper =>
+      new RegExp(`func \\(\\*?${wrapper}\\) ${iface}\\(\\)`).test(data)
+    )
+    .map(wrapper => {
+      const body = new RegExp(`type ${wrapper} struct {([^{}]*)}`).exec(data);
+      return (body ? body[1] : "")
+        .split("\n")
+        .find(line => /^\s*\w+\s+[^\s]+\s+`/.test(line));
+    })
+    .filter(Boolean);
+}
+
+/**
+ * JSON name of a protobuf field (`json=` option, or the lower camel case
+ * `name=` option, like protojson)
+ *
+ * @param {string} tag `protobuf` struct tag
+ * @return {string|undefined}
+ */
+function protobufName(tag = "") {
+  const options = {};
+  tag.split(",").forEach(option => {
+    const [key, value] = option.split("=");
+    if (value !== undefined) options[key] = value;
+  });
+  return (
+    options.json ||
+    (options.name &&
+      options.name.replace(/_([a-z])/g, (match, c) => c.toUpperCase()))
+  );
+}
+
+/**
+ * Extract the `go2dts:` directives of comment lines
+ *
+ * @param {string[]} lines
+ * @return {object} directive -> argument (`true` without argument)
+ */
+function parseDirectives(lines) {
+  const directives = {};
+  lines.forEach(line => {
+    const directive = /\/\/\s*go2dts:(\w+)(.*)$/.exec(line);
+    if (directive) directives[directive[1]] = directive[2].trim() || true;
+  });
+  return directives;
+}
+
+/**
+ * Extract the `go2dts:` directives of all the type declarations of a go file
+ *
+ * @param {string} data go source
+ * @return {object} `pkg.GoName` -> directives
+ */
+function parseTypeDirectives(data) {
+  const [, pkg = ""] = /^package (\w+)/m.exec(data) || [];
+  const types = {};
+  const typeRegex = /((?:^[ \t]*\/\/.*\n)*)^type (\w+)/gm;
+  let m;
+  while ((m = typeRegex.exec(data)) !== null) {
+    types[`${pkg}.${m[2]}`] = parseDirectives(m[1].split("\n"));
+  }
+  return types;
+}
+
+/**
+ * Extract the kind of all the type declarations of a go file
+ *
+ * @param {string} data go source
+ * @return {object} `pkg.GoName` -> kind
+ */
+function parseTypeKinds(data) {
+  const [, pkg = ""] = /^package (\w+)/m.exec(data) || [];
+  const types = {};
+  const typeRegex = /^type (\w+) (.*)$/gm;
+  let m;
+  while ((m = typeRegex.exec(data)) !== null) {
+    types[m[1]] = /^struct/.test(m[2]) ? "struct" : kindOf(m[2].trim());
+  }
+
+  // Named string types with typed const values are enums
+  const constRegex = /^[ \t]*\w+[ \t]+(\w+)[ \t]*=[ \t]*"/gm;
+  while ((m = constRegex.exec(data)) !== null) {
+    if (types[m[1]] === "scalar") types[m[1]] = "enum";
+  }
+
+  const qualified = {};
+  Object.keys(types).forEach(i => (qualified[`${pkg}.${i}`] = types[i]));
+  return qualified;
+}
+
+/**
+ * Extract the types written as JSON responses by a go file
+ *
+ * Detects the values (composite literals, or variables initialized with one)
+ * passed as last argument of the encoding and responding functions
+ * (`json.Marshal`, `Encode`, `JSONResponse`, `render.JSON`...).
+ *
+ * @param {string} data go source
+ * @return {string[]} `pkg.GoName` of the types
+ */
+function parseResponseTypes(data) {
+  const [, pkg = ""] = /^package (\w+)/m.exec(data) || [];
+  const variables = {};
+  const literalRegex = /\b(\w+)\s*:?=\s*&?(\w+)\{/g;
+  let m;
+  while ((m = literalRegex.exec(data)) !== null) variables[m[1]] = m[2];
+
+  const types = [];
+  const callRegex = /\b(?:\w*JSON\w*|\w*Respon\w*|Encode|Marshal|Render\w*)\(([^()]*)\)/g;
+  while ((m = callRegex.exec(data)) !== null) {
+    const value = m[1]
+      .split(",")
+      .pop()
+      .trim()
+      .replace(/^&/, "");
+    const [, literal] = /^(\w+)\{/.exec(value) || [];
+    const type = literal || variables[value];
+    if (type && !types.includes(`${pkg}.${type}`)) types.push(`${pkg}.${type}`);
+  }
+  return types;
+}
+
+/**
+ * Kind of a go type, as seen by `encoding/json`
+ *
+ * @param {string} t go type
+ * @param {object} typeKinds `pkg.GoName` -> kind of the parsed declarations
+ * @param {string} pkg package of the unqualified types
+ * @return {string|undefined} `pointer`, `slice`, `array`, `map`, `interface`,
+ * `integer`, `scalar`, `enum`, `struct` or `undefined` if unknown
+ */
+function kindOf(t, typeKinds = {}, pkg = "") {
+  if (/^\*/.test(t)) return "pointer";
+  if (/^\[\]/.test(t)) return "slice";
+  if (/^\[\w+\]/.test(t)) return "array";
+  if (/^map\[/.test(t)) return "map";
+  if (/^interface/.test(t)) return "interface";
+  if (/^u?int(8|16|32|64)?$/.test(t)) return "integer";
+  if (/^(string|bool|byte|rune|u?int(8|16|32|64)?|float(32|64))$/.test(t)) {
+    return "scalar";
+  }
+  return goKinds[t] || typeKinds[/\./.test(t) ? t : `${pkg}.${t}`];
+}
+
+/**
+ * Extract the struct tags of a field
+ *
+ * @param {string} i field declaration
+ * @return {object} tag key -> value
+ */
+function parseTags(i) {
+  const tags = {};
+  const [, tag = ""] = /`([^`]*)`/.exec(i) || [];
+  const tagRegex = /(\w+):"((?:[^"\\]|\\.)*)"/g;
+  let m;
+  while ((m = tagRegex.exec(tag)) !== null) {
+    tags[m[1]] = m[2].replace(/\\(.)/g, "$1");
+  }
+  return tags;
+}
+
+/**
+ * Parse a go type expression
+ *
+ * Nodes are one of:
+ *  - `{ kind: "pointer", elem }`
+ *  - `{ kind: "slice", elem }`
+ *  - `{ kind: "array", length, elem }`
+ *  - `{ kind: "map", key, value }`
+ *  - `{ kind: "named", name }` (builtin or declared types)
+ *
+ * @param {string} t go type
+ * @return {object}
+ */
+function parseGoType(t) {
+  const type = t.trim();
+  if (type.startsWith("*")) {
+    return { kind: "pointer", elem: parseGoType(type.slice(1)) };
+  }
+  if (type.startsWith("[]")) {
+    return { kind: "slice", elem: parseGoType(type.slice(2)) };
+  }
+  const array = /^\[(\w+)\]/.exec(type);
+  if (array) {
+    const elem = parseGoType(type.slice(array[0].length));
+    return { kind: "array", length: array[1], elem };
+  }
+  if (type.startsWith("map[")) {
+    // The key ends on the matching bracket (`map[[2]int]string`)
+    let depth = 0;
+    let end = 3;
+    for (; end < type.length; end++) {
+      if (type[end] === "[") depth++;
+      if (type[end] === "]" && --depth === 0) break;
+    }
+    return {
+      kind: "map",
+      key: parseGoType(type.slice(4, end)),
+      value: parseGoType(type.slice(end + 1))
+    };
+  }
+  return { kind: "named", name: type };
+}
+
+/**
+ * Typescript type of a parsed go type
+ *
+ * Maps are emitted as `{[key: string]: T}`, except for the string enums keys
+ * (`Partial<Record<Enum, T>>`, not all the values are present) and the
+ * integers keys (`Record<string, T>`, JSON object keys are strings).
+ *
+ * `encoding/json` encodes the byte slices as base64 strings, but the byte
+ * arrays as numbers arrays. Fixed-size arrays are emitted as tuples, up to
+ * `maxTupleLength` elements.
+ *
+ * The pointers of the field itself are handled by its nullability, the nested
+ * ones (`[]*T`, `map[string]*T`) are `null` on the wire when nil, and are
+ * emitted as nullable with `nullableElements`.
+ *
+ * @param {object} node see `parseGoType`
+ * @param {object} options
+ * @param {function} options.resolveType go type -> typescript type
+ * @param {function} options.kindOf
+ * @param {number} options.maxTupleLength
+ * @param {boolean} options.nullableElements
+ * @param {boolean} [options.nested] `node` is an element of a slice or a map
+ * @return {string}
+ */
+function toTsType(node, options) {
+  const { resolveType, kindOf, maxTupleLength, nullableElements } = options;
+  const nested = Object.assign({}, options, { nested: true });
+  switch (node.kind) {
+    case "pointer":
+      let target = node.elem;
+      while (target.kind === "pointer") target = target.elem;
+      const pointed = toTsType(target, options);
+      return options.nested && nullableElements && !/\bnull\b/.test(pointed)
+        ? `${pointed} | null`
+        : pointed;
+    case "slice":
+    case "array":
+      const isByte = node.elem.kind === "named" && isByteType(node.elem.name);
+      if (node.kind === "slice" && isByte) return "string";
+
+      const elem = toTsType(node.elem, nested);
+      const length = Number(node.length);
+      if (node.kind === "array" && length <= maxTupleLength) {
+        return `[${Array(length)
+          .fill(elem)
+          .join(", ")}]`;
+      }
+      return / [|&] /.test(elem) ? `(${elem})[]` : `${elem}[]`;
+    case "map":
+      const value = toTsType(node.value, nested);
+      const key = node.key.kind === "named" ? node.key.name : "";
+      if (kindOf(key) === "enum") {
+        const keyType = toTsType(node.key, options);
+        return `Partial<Record<${keyType}, ${value}>>`;
+      }
+      if (kindOf(key) === "integer") return `Record<string, ${value}>`;
+      return `{[key: string]: ${value}}`;
+    default:
+      return resolveType(node.name);
+  }
+}
+
+/**
+ * `byte` and its alias `uint8`
+ *
+ * @param {string} t go type
+ * @return {boolean}
+ */
+const isByteType = t => t === "byte" || t === "uint8";
+
+/**
+ * Optionality and nullability of a field, following the `encoding/json` rules:
+ *  - `omitempty` omits nil pointers, empty slices/maps and zero scalars,
+ *    but never structs
+ *  - nil pointers, slices and maps are encoded as `null`
+ *
+ * @param {string} t go type
+ * @param {boolean} omitempty
+ * @param {function} kindOf
+ * @return {object} `{ optional, nullable }`
+ */
+function strictNullability(t, omitempty, kindOf) {
+  const pointer = kindOf(t) === "pointer";
+  const kind = kindOf(t.replace(/^\*+/, ""));
+  const nilable = ["slice", "map", "interface"].includes(kind);
+  const omittable = pointer || nilable || !["struct", "array"].includes(kind);
+
+  return {
+    optional: omitempty && omittable,
+    nullable: (pointer && !omitempty) || (nilable && (pointer || !omitempty))
+  };
+}
+
+function parseParameter(i, resolveType, options = {}) {
+  const {
+    directives = {},
+    kindOf = () => undefined,
+    strict = false,
+    int64String = "string",
+    maxTupleLength = 0,
+    nullableElements = false,
+    protojson = false
+  } = options;
+  const tags = parseTags(i);
+  // `XXX_` fields are the protobuf bookkeeping
+  if (tags.json === "-" || directives.ignore || /^\s*XXX_/.test(i)) {
+    return { internal: true };
+  }
+
+  try {
+    const [, fieldName, t] = /\t(\w*) *([a-zA-Z_0-9.*\[\]]+) *`/.exec(i);
+    const [jsonName, ...jsonOptions] = (tags.json || "").split(",");
+    const omitempty = jsonOptions.includes("omitempty");
+
+    // protojson uses the protobuf json name, and omits the unpopulated fields
+    // (only the proto2 `req` fields are always present)
+    const isProtojson = protojson && Boolean(tags.protobuf);
+    const name =
+      directives.name ||
+      (isProtojson ? protobufName(tags.protobuf) : jsonName) ||
+      fieldName;
+    // Without `nullableElements`, the fields with pointer elements are
+    // optional (`[]*T` -> `data?: T[]`)
+    const pointer = nullableElements ? /^\*/ : /\*/;
+    const { optional, nullable } = isProtojson
+      ? { optional: !tags.protobuf.split(",").includes("req"), nullable: false }
+      : strict
+        ? strictNullability(t, omitempty, kindOf)
+        : { optional: pointer.test(t) || omitempty, nullable: false };
+    const tsType = directives.type || tags.tstype || tags.ts;
+
+    // Scalars encoded as strings (`,string` option), protojson encodes the
+    // 64-bit integers as strings (`encoding/json` as numbers)
+    const quoted = jsonOptions.includes("string") && /^\**\w+$/.test(t);
+    const resolveScalar = type => {
+      if (/^u?int64$/.test(type) && (quoted || isProtojson)) {
+        return int64String;
+      }
+      if (quoted && /^(u?int(8|16|32)?|float(32|64)|bool)$/.test(type)) {
+        return "string";
+      }
+      return resolveType(type);
+    };
+
+    const type =
+      tsType ||
+      toTsType(parseGoType(t), {
+        resolveType: resolveScalar,
+        kindOf,
+        maxTupleLength,
+        nullableElements
+      });
+
+    return {
+      type,
+      name,
+      optional: optional || Boolean(directives.optional),
+      required: Boolean(directives.required),
+      nullable: nullable && !tsType
+    };
+  } catch (e) {
+    console.log(e);
+    console.log(`${i} can't be parsed`);
+  }
+}
+
+module.exports = {
+  parseFile,
+  parseImports,
+  parseResponseTypes,
+  parseTypeDirectives,
+  parseTypeKinds,
+  referenceResolver
+};
@@ -0,0 +1,289 @@
+// Typescript types of the protobuf scalars (protojson encoding), the 64-bit
+// integers are encoded as strings
+const scalarTypes = {
+  double: "number",
+  float: "number",
+  int32: "number",
+  uint32: "number",
+  sint32: "number",
+  fixed32: "number",
+  sfixed32: "number",
+  int64: "int64",
+  uint64: "int64",
+  sint64: "int64",
+  fixed64: "int64",
+  sfixed64: "int64",
+  bool: "boolean",
+  string: "string",
+  bytes: "string"
+};
+
+// Go packages of the well-known types (`google.protobuf.Timestamp`)
+const wellKnownPackages = {
+  Timestamp: "timestamppb",
+  Duration: "durationpb",
+  Struct: "structpb",
+  Value: "structpb",
+  ListValue: "structpb",
+  NullValue: "structpb",
+  Any: "anypb",
+  FieldMask: "fieldmaskpb",
+  Empty: "emptypb"
+};
+
+/**
+ * Split a proto source in tokens (the comments are dropped)
+ *
+ * @param {string} data proto source
+ * @return {string[]}
+ */
+function tokenize(data) {
+  const tokenRegex = /\/\/.*|\/\*[^]*?\*\/|"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|\.?[A-Za-z_][\w.]*|-?[\d][\w.]*|[^\s]/g;
+  return (data.match(tokenRegex) || []).filter(i => !/^\/[/*]/.test(i));
+}
+
+/**
+ * Package name of a proto file, as the go package (`go_package` option, or
+ * last segment of the proto package without its version)
+ *
+ * @param {string} data proto source
+ * @return {string}
+ */
+function protoPackage(data) {
+  const [, goPackage] = /option\s+go_package\s*=\s*"([^"]*)"/.exec(data) || [];
+  if (goPackage) return goPackage.split(";").pop().split("/").pop();
+  const [, pkg = ""] = /^\s*package\s+([\w.]+)\s*;/m.exec(data) || [];
+  return pkg
+    .split(".")
+    .filter(i => !/^v\d+\w*$/.test(i))
+    .pop();
+}
+
+/**
+ * Parse the messages and enums of a proto file (the nested ones are named
+ * like the generated go types, `Outer_Inner`)
+ *
+ * @param {string[]} tokens
+ * @return {object[]} `{ kind: "message", path, fields }` and
+ * `{ kind: "enum", path, values }`
+ */
+function parseDefinitions(tokens) {
+  const definitions = [];
+  let pos = 0;
+  const next = () => tokens[pos++];
+  const expect = token => {
+    if (tokens[pos] === token) pos++;
+  };
+
+  // Skip a statement, or a block
+  const skip = () => {
+    let depth = 0;
+    while (pos < tokens.length) {
+      const token = next();
+      if (token === "{") depth++;
+      if (token === "}" && --depth === 0) return;
+      if (token === ";" && depth === 0) return;
+    }
+  };
+
+  // `[json_name = "name", deprecated = true]`
+  const parseOptions = () => {
+    const options = {};
+    if (tokens[pos] !== "[") return options;
+    next();
+    while (pos < tokens.length && tokens[pos] !== "]") {
+      const key = next().replace(/^\(|\)$/g, "");
+      expect("=");
+      const value = next();
+      options[key] = value.replace(/^["']|["']$/g, "");
+      expect(",");
+    }
+    next();
+    return options;
+  };
+
+  const parseField = (label, oneof) => {
+    let type = next();
+    let key;
+    if (type === "map") {
+      expect("<");
+      key = next();
+      expect(",");
+      type = next();
+      expect(">");
+    }
+    const name = next();
+    expect("=");
+    next();
+    const options = parseOptions();
+    expect(";");
+    return {
+      name,
+      type,
+      key,
+      repeated: label === "repeated",
+      required: label === "required",
+      oneof,
+      jsonName: options.json_name
+    };
+  };
+
+  const parseEnum = path => {
+    const values = [];
+    expect("{");
+    while (pos < tokens.length && tokens[pos] !== "}") {
+      if (["option", "reserved"].includes(tokens[pos])) {
+        skip();
+        continue;
+      }
+      const name = next();
+      expect("=");
+      const number = Number(next());
+      parseOptions();
+      expect(";");
+      values.push({ name, number });
+    }
+    next();
+    definitions.push({ kind: "enum", path, values });
+  };
+
+  const parseMessage = path => {
+    const fields = [];
+    definitions.push({ kind: "message", path, fields });
+    expect("{");
+    while (pos < tokens.length && tokens[pos] !== "}") {
+      const token = next();
+      if (token === "message") parseMessage(path.concat(next()));
+      else if (token === "enum") parseEnum(path.concat(next()));
+      else if (token === "oneof") {
+        const oneof = next();
+        expect("{");
+        while (pos < tokens.length && tokens[pos] !== "}") {
+          if (tokens[pos] === "option") skip();
+          else fields.push(parseField(undefined, oneof));
+        }
+        next();
+      } else if (["repeated", "optional", "required"].includes(token)) {
+        fields.push(parseField(token));
+      } else if (token === "map") {
+        pos--;
+        fields.push(parseField());
+      } else if (
+        ["option", "reserved", "extensions", "extend", "group"].includes(token)
+      ) {
+        pos--;
+        skip();
+      } else if (token !== ";") {
+        pos--;
+        fields.push(parseField());
+      }
+    }
+    next();
+  };
+
+  while (pos < tokens.length) {
+    const token = next();
+    if (token === "message") parseMessage([next()]);
+    else if (token === "enum") parseEnum([next()]);
+    else if (token !== ";") {
+      pos--;
+      skip();
+    }
+  }
+  return definitions;
+}
+
+/**
+ * Parse a proto file into the declarations of the go parser (see
+ * `parseFile`), as encoded by protojson
+ *
+ * @param {string} data proto source
+ * @param {object} options
+ * @param {function} options.resolveType (goType, goImports, pkg) => typescript
+ * type
+ * @param {string} options.int64String type of the 64-bit integers encoded
+ * as strings
+ * @param {boolean} options.enumsAsInts emit the enums as their numbers
+ * @return {object[]} declarations
+ */
+function parseProto(data, options) {
+  const { resolveType, int64String, enumsAsInts } = options;
+  const pkg = protoPackage(data);
+  const [, protoPkg = ""] = /^\s*package\s+([\w.]+)\s*;/m.exec(data) || [];
+  const definitions = parseDefinitions(tokenize(data));
+  const goNames = definitions.map(d => d.path.join("_"));
+
+  // Reference to a message or an enum, looked up from the innermost scope
+  const resolveReference = (type, scope) => {
+    const name = type.replace(/^\./, "");
+    if (scalarTypes[name]) {
+      return scalarTypes[name] === "int64" ? int64String : scalarTypes[name];
+    }
+
+    const wellKnown = /^google\.protobuf\.(\w+)$/.exec(name);
+    if (wellKnown) {
+      const goPkg = /\w+Value$/.test(wellKnown[1])
+        ? wellKnownPackages[wellKnown[1]] || "wrapperspb"
+        : wellKnownPackages[wellKnown[1]];
+      return resolveType(`${goPkg}.${wellKnown[1]}`, {}, pkg);
+    }
+
+    const local =
+      protoPkg && name.startsWith(`${protoPkg}.`)
+        ? name.slice(protoPkg.length + 1)
+        : name;
+    for (let i = scope.length; i >= 0; i--) {
+      const goName = scope
+        .slice(0, i)
+        .concat(local.split("."))
+        .join("_");
+      if (goNames.includes(goName)) return `${pkg}.${goName}`;
+    }
+
+    // Message of another package
+    const segments = name.split(".");
+    return segments.length > 1 ? segments.slice(-2).join(".") : name;
+  };
+
+  const fieldType = (field, scope) => {
+    const value = resolveReference(field.type, scope);
+    if (field.key) {
+      return /^(string|bool)$/.test(field.key)
+        ? `{[key: string]: ${value}}`
+        : `Record<string, ${value}>`;
+    }
+    if (!field.repeated) return value;
+    return / [|&] /.test(value) ? `(${value})[]` : `${value}[]`;
+  };
+
+  return definitions.map(definition => {
+    const goName = definition.path.join("_");
+    if (definition.kind === "enum") {
+      const members = definition.values.map(({ name, number }) => ({
+        key: name,
+        value: enumsAsInts ? number : name
+      }));
+      const values = members.map(i => i.value);
+      return { kind: "enum", name: goName, goName, pkg, values, members };
+    }
+
+    return {
+      kind: "interface",
+      name: goName,
+      goName,
+      pkg,
+      fields: definition.fields.map(field => ({
+        type: fieldType(field, definition.path),
+        name:
+          field.jsonName ||
+          field.name.replace(/_([a-z])/g, (match, c) => c.toUpperCase()),
+        optional: !field.oneof && !field.required,
+        required: false,
+        nullable: false,
+        oneof: field.oneof
+      }))
+    };
+  });
+}
+
+module.exports = { parseProto, protoPackage };
@@ -0,0 +1,127 @@
+// Packages of the protobuf well-known types (import path -> package)
+const wellKnownPackages = {
+  "google.golang.org/protobuf/types/known/timestamppb": "timestamppb",
+  "github.com/golang/protobuf/ptypes/timestamp": "timestamppb",
+  "google.golang.org/protobuf/types/known/durationpb": "durationpb",
+  "github.com/golang/protobuf/ptypes/duration": "durationpb",
+  "google.golang.org/protobuf/types/known/wrapperspb": "wrapperspb",
+  "github.com/golang/protobuf/ptypes/wrappers": "wrapperspb",
+  "google.golang.org/protobuf/types/known/structpb": "structpb",
+  "github.com/golang/protobuf/ptypes/struct": "structpb",
+  "google.golang.org/protobuf/types/known/anypb": "anypb",
+  "github.com/golang/protobuf/ptypes/any": "anypb",
+  "google.golang.org/protobuf/types/known/fieldmaskpb": "fieldmaskpb",
+  "google.golang.org/genproto/protobuf/field_mask": "fieldmaskpb",
+  "google.golang.org/protobuf/types/known/emptypb": "emptypb",
+  "github.com/golang/protobuf/ptypes/empty": "emptypb"
+};
+
+// Packages marshalling the protobuf messages with the proto3 JSON mapping
+const protojsonPackages = [
+  "google.golang.org/protobuf/encoding/protojson",
+  "github.com/golang/protobuf/jsonpb",
+  "github.com/grpc-ecosystem/grpc-gateway/runtime",
+  "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+];
+
+/**
+ * Is a go file marshalling the protobuf messages with protojson
+ *
+ * @param {object} goImports package name -> import path
+ * @return {boolean}
+ */
+const usesProtojson = goImports =>
+  Object.keys(goImports).some(i => protojsonPackages.includes(goImports[i]));
+
+/**
+ * Wrappers primitive types (`wrapperspb.StringValue` -> `string`)
+ *
+ * @param {string} int64Type type of the 64-bit integers
+ * @return {object} wrapper -> typescript type
+ */
+const wrappers = int64Type => ({
+  DoubleValue: "number",
+  FloatValue: "number",
+  Int32Value: "number",
+  UInt32Value: "number",
+  Int64Value: int64Type,
+  UInt64Value: int64Type,
+  BoolValue: "boolean",
+  StringValue: "string",
+  BytesValue: "string"
+});
+
+/**
+ * Typescript type of a protobuf well-known type.
+ *
+ * The proto3 JSON mapping (`protojson`, `jsonpb`) encodes them as their JSON
+ * form (a `Timestamp` is a RFC 3339 string, a `Duration` is `"1.5s"`...),
+ * `encoding/json` as the go structs.
+ *
+ * @param {string} pkg import path (or package name) of the type
+ * @param {string} name type name
+ * @param {object} options
+ * @param {boolean} options.protojson
+ * @param {string} options.int64String type of the 64-bit integers encoded
+ * as strings (protojson)
+ * @param {string} options.int64Type type of the 64-bit integers encoded as
+ * numbers (`encoding/json`)
+ * @return {string|undefined} `undefined` if not a well-known type
+ */
+const wellKnownType = (pkg, name, options) => {
+  const { protojson, int64String, int64Type } = options;
+  const wellKnown =
+    wellKnownPackages[pkg] ||
+    (Object.values(wellKnownPackages).includes(pkg) ? pkg : undefined);
+
+  if (protojson) {
+    switch (`${wellKnown}.${name}`) {
+      case "timestamppb.Timestamp":
+        return "Time";
+      case "durationpb.Duration":
+      case "fieldmaskpb.FieldMask":
+        return "string";
+      case "structpb.Struct":
+        return "{[key: string]: unknown}";
+      case "structpb.Value":
+        return "unknown";
+      case "structpb.ListValue":
+        return "unknown[]";
+      case "structpb.NullValue":
+        return "null";
+      case "anypb.Any":
+        return `{"@type": string, [key: string]: unknown}`;
+      case "emptypb.Empty":
+        return "{}";
+    }
+    const wrapper = wellKnown === "wrapperspb" && wrappers(int64String)[name];
+    return wrapper ? `${wrapper} | null` : undefined;
+  }
+
+  switch (`${wellKnown}.${name}`) {
+    case "timestamppb.Timestamp":
+    case "durationpb.Duration":
+      return `{seconds?: ${int64Type}, nanos?: number}`;
+    case "fieldmaskpb.FieldMask":
+      return "{paths?: string[]}";
+    case "structpb.Struct":
+      return "{fields?: {[key: string]: unknown}}";
+    case "structpb.Value":
+      return "unknown";
+    case "structpb.ListValue":
+      return "{values?: unknown[]}";
+    case "structpb.NullValue":
+      return "number";
+    case "anypb.Any":
+      return "{type_url?: string, value?: string}";
+    case "emptypb.Empty":
+      return "{}";
+  }
+  const wrapper = wellKnown === "wrapperspb" && wrappers(int64Type)[name];
+  return wrapper ? `{value?: ${wrapper}}` : undefined;
+};
+
+module.exports = {
+  usesProtojson,
+  wellKnownType
+};
@@ -0,0 +1,173 @@
+const { parseTsType } = require("./decoders");
+
+/**
+ * Shape of the 64-bit integers of a type: `"int64"`, the name of a type
+ * holding some, or the shapes by key (`"*"` stands for the elements of the
+ * arrays and the values of the maps)
+ *
+ * @param {object} node parsed type (see `parseTsType`)
+ * @param {Set<string>} holders names of the types holding 64-bit integers
+ * @return {string|object|undefined} `undefined` without 64-bit integers
+ */
+function int64Shape(node, holders) {
+  switch (node.kind) {
+    case "union":
+      const shapes = node.types.map(i => int64Shape(i, holders));
+      return shapes.find(Boolean);
+    case "array":
+      const element = int64Shape(node.element, holders);
+      return element && { "*": element };
+    case "map":
+      const value = int64Shape(node.value, holders);
+      return value && { "*": value };
+    case "tuple":
+      const elements = node.elements.map(i => int64Shape(i, holders));
+      if (!elements.some(Boolean)) return;
+      const tuple = {};
+      elements.forEach((shape, index) => shape && (tuple[index] = shape));
+      return tuple;
+    case "ref":
+      if (node.name === "bigint") return "int64";
+      if (holders.has(node.name)) return node.name;
+  }
+}
+
+/**
+ * Shapes of the 64-bit integers of every type holding some, at any depth
+ * (the embedded types fields included)
+ *
+ * @param {object[]} declarations
+ * @return {object} type name -> shape
+ */
+function int64Shapes(declarations) {
+  const byName = {};
+  declarations.forEach(d => (byName[d.name] = d));
+  const fieldsOf = d =>
+    (d.fields || []).concat(
+      d.parent && byName[d.parent] ? fieldsOf(byName[d.parent]) : []
+    );
+  const shapeOf = (d, holders) => {
+    if (d.kind === "alias") return int64Shape(parseTsType(d.type), holders);
+    if (d.kind !== "interface") return;
+    const shape = {};
+    fieldsOf(d).forEach(f => {
+      const field = int64Shape(parseTsType(f.type), holders);
+      if (field) shape[f.name] = field;
+    });
+    return Object.keys(shape).length ? shape : undefined;
+  };
+
+  const holders = new Set();
+  let size;
+  do {
+    size = holders.size;
+    declarations
+      .filter(d => shapeOf(d, holders))
+      .forEach(d => holders.add(d.name));
+  } while (holders.size !== size);
+
+  const shapes = {};
+  declarations
+    .filter(d => holders.has(d.name))
+    .forEach(d => (shapes[d.name] = shapeOf(d, holders)));
+  return shapes;
+}
+
+/**
+ * Emit the decoders converting the 64-bit integers to `bigint`, following
+ * the types (`decodeInt64("Job", json)`, `parseInt64JSON("Job", text)`)
+ *
+ * @param {object[]} declarations
+ * @return {string} typescript module
+ */
+const emitInt64Reviver = declarations => {
+  const shapes = int64Shapes(declarations);
+  const names = Object.keys(shapes).map(i => JSON.stringify(i));
+  return `// Generated by go2dts
+
+export type Int64Type = ${names.join(" | ") || "never"};
+
+type Shape = string | { [key: string]: Shape };
+type Sources = WeakMap<object, { [key: string]: string }>;
+
+// Shapes of the 64-bit integers of every type (\`*\` stands for the elements
+// of the arrays and the values of the maps)
+const int64Shapes: { [type: string]: Shape } = ${JSON.stringify(
+    shapes,
+    null,
+    2
+  )};
+
+const toBigInt = (value: unknown, source?: string): unknown => {
+  if (typeof value === "number" && Number.isInteger(value)) {
+    return BigInt(source && /^-?\\d+$/.test(source) ? source : value);
+  }
+  if (typeof value === "string" && /^-?\\d+$/.test(value)) {
+    return BigInt(value);
+  }
+  return value;
+};
+
+const decode = (
+  value: any,
+  shape: Shape,
+  sources: Sources,
+  holder?: object,
+  key?: string
+): unknown => {
+  if (value == null) return value;
+  if (shape === "int64") {
+    const source = holder && key !== undefined && sources.get(holder);
+    return toBigInt(value, source ? source[key as string] : undefined);
+  }
+  const fields = typeof shape === "string" ? int64Shapes[shape] : shape;
+  if (typeof fields === "string") {
+    return decode(value, fields, sources, holder, key);
+  }
+  if (typeof value !== "object") return value;
+  Object.keys(fields).forEach(field => {
+    const keys =
+      field === "*" ? Object.keys(value) : field in value ? [field] : [];
+    keys.forEach(i => {
+      value[i] = decode(value[i], fields[field], sources, value, i);
+    });
+  });
+  return value;
+};
+
+/**
+ * Convert the 64-bit integers of a parsed JSON value of a type to \`bigint\`
+ * (in place). Numbers above \`Number.MAX_SAFE_INTEGER\` keep their precision
+ * only if they are sent as strings, use \`parseInt64JSON\` otherwise.
+ */
+export const decodeInt64 = <T>(type: Int64Type, json: unknown): T =>
+  decode(json, type, new WeakMap()) as T;
+
+/**
+ * Parse a JSON value of a type, with its 64-bit integers as \`bigint\`.
+ *
+ * Numbers above \`Number.MAX_SAFE_INTEGER\` keep their precision if the
+ * runtime gives access to the source text of the JSON values.
+ */
+export const parseInt64JSON = <T>(type: Int64Type, text: string): T => {
+  const sources: Sources = new WeakMap();
+  const json = JSON.parse(text, function(
+    this: object,
+    key: string,
+    value: unknown,
+    context?: { source?: string }
+  ) {
+    const unsafe = typeof value === "number" && !Number.isSafeInteger(value);
+    if (unsafe && context && context.source) {
+      const holder = sources.get(this) || {};
+      holder[key] = context.source;
+      sources.set(this, holder);
+    }
+    return value;
+  });
+  return decode(json, type, sources) as T;
+};
+`;
+};
+
+module.exports = emitInt64Reviver;
@@ -0,0 +1,231 @@
+const chalk = require("chalk");
+const { parseImports, referenceResolver } = require("./parser");
+
+// Chi shortcuts (`r.Get(pattern, handler)`)
+const chiMethods = ["Get", "Post", "Put", "Patch", "Delete", "Head", "Options"];
+
+/**
+ * Split the arguments of a call, from its opening parenthesis
+ * (`"/users", h.get).Methods("GET")` -> `["\"/users\"", "h.get"]`)
+ *
+ * @param {string} rest source following the opening parenthesis
+ * @return {object} `{ args, after }`, `after` following the closing one
+ */
+function splitCall(rest) {
+  const args = [];
+  let depth = 0;
+  let arg = "";
+  for (let i = 0; i < rest.length; i++) {
+    const c = rest[i];
+    if (c === ")" && depth === 0) {
+      if (arg.trim()) args.push(arg.trim());
+      return { args, after: rest.slice(i + 1) };
+    }
+    if ("([{".includes(c)) depth++;
+    if (")]}".includes(c)) depth--;
+    if (c === "," && depth === 0) {
+      args.push(arg.trim());
+      arg = "";
+    } else arg += c;
+  }
+  return { args, after: "" };
+}
+
+/**
+ * HTTP method of a go expression (`http.MethodGet` or `"GET"`)
+ *
+ * @param {string} expression
+ * @return {string}
+ */
+const httpMethod = expression =>
+  expression
+    .trim()
+    .replace(/^http\.Method|"/g, "")
+    .toUpperCase();
+
+/**
+ * Extract the route registrations of a go file
+ *
+ * Supports `net/http` (`http.HandleFunc("GET /users", h)`), gorilla/mux
+ * (`r.HandleFunc("/users", h).Methods("GET")`, with the `PathPrefix`
+ * subrouters) and chi (`r.Get("/users", h)`, with the nested `r.Route`).
+ *
+ * @param {string} data go source
+ * @return {object[]} `{ method, path, handler, qualifier }`, the method is
+ * undefined for the routes matching all of them, the qualifier is the package
+ * or the receiver of the handler (`h` of `h.listUsers`)
+ */
+function parseRegistrations(data) {
+  const registrations = [];
+  const prefixes = {}; // router variable -> path prefix
+  const scopes = []; // chi `r.Route` prefixes, with their depth
+  let depth = 0;
+
+  data.split("\n").forEach(line => {
+    const scope = scopes.map(i => i.prefix).join("");
+    const register = (method, pattern, router, handlerExpression) => {
+      const [, patternMethod, path] = /^(?:([A-Z]+) )?(.*)$/.exec(pattern);
+      const [, qualifier, handler] =
+        /(?:(\w+)\.)?(\w+)(?:\(\))?\)*$/.exec(handlerExpression) || [];
+      registrations.push({
+        method: method || patternMethod,
+        path: (scope + (prefixes[router] || "") + path)
+          .replace(/\{(\w+):[^}]*\}/g, "{$1}")
+          .replace(/(.)\/$/, "$1"),
+        handler,
+        qualifier
+      });
+    };
+
+    const subrouter = /\b(\w+) :?= (\w+)\.PathPrefix\("([^"]*)"\)\.Subrouter\(\)/.exec(
+      line
+    );
+    if (subrouter) {
+      const [, router, parent, prefix] = subrouter;
+      prefixes[router] = (prefixes[parent] || "") + prefix;
+    }
+
+    const call = /\b(\w+)\.(\w+)\((.*)$/.exec(line);
+    if (call) {
+      const [, router, fn, rest] = call;
+      const { args, after } = splitCall(rest);
+      const [, pattern] = /^"([^"]*)"$/.exec(args[0] || "") || [];
+      if (fn === "Route" && pattern !== undefined) {
+        scopes.push({ prefix: pattern, depth: depth + 1 });
+      } else if (/^Handle(Func)?$/.test(fn) && pattern !== undefined) {
+        const [, methods] = /^\.Methods\(([^)]*)\)/.exec(after) || [];
+        (methods ? methods.split(",").map(httpMethod) : [undefined]).forEach(
+          method => register(method, pattern, router, args[1])
+        );
+      } else if (chiMethods.includes(fn) && pattern !== undefined) {
+        register(fn.toUpperCase(), pattern, router, args[1]);
+      } else if (/^Method(Func)?$/.test(fn) && args.length === 3) {
+        const [, path] = /^"([^"]*)"$/.exec(args[1]) || [];
+        if (path !== undefined) {
+          register(httpMethod(args[0]), path, router, args[2]);
+        }
+      }
+    }
+
+    depth += (line.match(/{/g) || []).length;
+    depth -= (line.match(/}/g) || []).length;
+    while (scopes.length && depth < scopes[scopes.length - 1].depth) {
+      scopes.pop();
+    }
+  });
+  return registrations;
+}
+
+/**
+ * Extract the routes of the server packages, as a `Routes` declaration keyed
+ * by method and path (`"GET /api/users/{id}"`), their request and response
+ * types being inferred from the handlers:
+ *  - request: `json.NewDecoder(r.Body).Decode(&x)`
+ *  - response: `JSONResponse(w, x)`, `JSONResponseWithCode(w, code, x)` or
+ *    `json.NewEncoder(w).Encode(x)`
+ *
+ * @param {string[]} files go sources
+ * @param {object} options
+ * @param {function} options.resolveType (goType, goImports, pkg) => typescript
+ * type
+ * @param {object} options.typeKinds `pkg.GoName` -> kind (see `parseTypeKinds`)
+ * @return {object[]} `{ kind: "routes", name, goName, pkg, fields }`, if any
+ * route
+ */
+function parseRoutes(files, { resolveType, typeKinds }) {
+  // Functions of every package (package -> name -> { body, resolveReference })
+  const functions = {};
+  files.forEach(data => {
+    const [, pkg = ""] = /^package (\w+)/m.exec(data) || [];
+    const resolveReference = referenceResolver(data, {
+      resolveType,
+      typeKinds
+    });
+    functions[pkg] = functions[pkg] || {};
+    const funcRegex = /^func (?:\([^)]*\) )?(\w+)\([^]*?{\n([^]*?)\n}/gm;
+    let m;
+    while ((m = funcRegex.exec(data)) !== null) {
+      functions[pkg][m[1]] = { body: m[2], resolveReference };
+    }
+  });
+
+  // Type of a value in a handler: composite literal, or variable declared by
+  // `var x T`, `x := T{}`, `x := new(T)`
+  const typeOf = (value, body, resolveReference) => {
+    const tsType = type => {
+      if (/^\*/.test(type)) return tsType(type.slice(1));
+      if (/^\[\]/.test(type)) return `${tsType(type.slice(2))}[]`;
+      return resolveReference(type);
+    };
+    const expression = value.replace(/^&/, "");
+    const [, literal] = /^((?:\[\])?[\w.]+){/.exec(expression) || [];
+    if (literal) return tsType(literal);
+    if (!/^\w+$/.test(expression)) return "unknown";
+
+    const declaration =
+      new RegExp(`\\bvar ${expression} ([\\w.*\\[\\]]+)`).exec(body) ||
+      new RegExp(`\\b${expression} :?= &?((?:\\[\\])?[\\w.]+){`).exec(body) ||
+      new RegExp(`\\b${expression} :?= new\\(([\\w.]+)\\)`).exec(body);
+    return declaration ? tsType(declaration[1]) : "unknown";
+  };
+
+  const union = types =>
+    types.filter((type, i) => types.indexOf(type) === i).join(" | ") || "void";
+
+  // Package name of an import path (`github.com/go-chi/chi/v5` -> `chi`)
+  const packageName = path => Object.keys(parseImports(`import "${path}"`))[0];
+
+  const fields = [];
+  files.forEach(data => {
+    const [, pkg = ""] = /^package (\w+)/m.exec(data) || [];
+    const goImports = parseImports(data);
+    parseRegistrations(data).forEach(({ method, path, handler, qualifier }) => {
+      const name = method ? `${method} ${path}` : path;
+      if (fields.some(i => i.name === name)) return;
+
+      // Handlers of the imported packages, or of the registering one
+      const handlerPkg = goImports[qualifier]
+        ? packageName(goImports[qualifier])
+        : pkg;
+      const { body, resolveReference } =
+        (functions[handlerPkg] || {})[handler] || {};
+      const requests = [];
+      const responses = [];
+      if (resolveReference) {
+        const decodeRegex = /json\.NewDecoder\(\w+\.Body\)\.Decode\(([^()]+)\)/g;
+        const encodeRegex = /\b(?:JSONResponse(?:WithCode)?\(|json\.NewEncoder\(\w+\)\.Encode\()((?:[^()]|\([^()]*\))*)\)/g;
+        let m;
+        while ((m = decodeRegex.exec(body)) !== null) {
+          requests.push(typeOf(m[1].trim(), body, resolveReference));
+        }
+        while ((m = encodeRegex.exec(body)) !== null) {
+          const value = splitCall(`${m[1]})`).args.pop();
+          responses.push(typeOf(value, body, resolveReference));
+        }
+      } else {
+        console.log(
+          `${chalk.yellow("Warning:")} handler "${
+            qualifier ? `${qualifier}.` : ""
+          }${handler}" of the route "${name}" not found`
+        );
+        requests.push("unknown");
+        responses.push("unknown");
+      }
+
+      fields.push({
+        name,
+        type: `{ request: ${union(requests)}; response: ${union(responses)} }`,
+        optional: false,
+        required: false,
+        nullable: false
+      });
+    });
+  });
+
+  if (!fields.length) return [];
+  return [
+    { kind: "routes", name: "Routes", goName: "Routes", pkg: "", fields }
+  ];
+}
+
+module.exports = { parseRoutes };
@@ -0,0 +1,99 @@
+// Policies of the unexported go types
+const policies = ["include", "skip", "referenced"];
+
+/**
+ * Go references (`pkg.GoName`, see `parseFile`) of a typescript type
+ *
+ * @param {string} type
+ * @return {string[]}
+ */
+const goReferences = type =>
+  type.replace(/"[^"]*"|'[^']*'/g, "").match(/\b\w+\.\w+\b/g) || [];
+
+/**
+ * Apply the `unexported` policy:
+ *  - `include` (default): the unexported types are emitted like the others
+ *  - `skip`: the unexported types are skipped, and typed `unknown` where
+ *    they're referenced
+ *  - `referenced`: the unexported types are emitted only if an emitted type
+ *    or a JSON response references them
+ *
+ * The types with a `//go2dts:export` directive, and the HTTP clients, are
+ * always emitted.
+ *
+ * @param {object[]} declarations
+ * @param {string} policy
+ * @param {string[]} roots `pkg.GoName` of the types emitted anyway (forced or
+ * written as JSON responses)
+ * @return {object[]} declarations
+ */
+const applyUnexported = (declarations, policy = "include", roots = []) => {
+  if (!policies.includes(policy)) {
+    throw new Error(`Unknown unexported policy "${policy}"`);
+  }
+  if (policy === "include") return declarations;
+
+  const key = d => `${d.pkg}.${d.goName}`;
+  const byKey = {};
+  declarations.forEach(d => (byKey[key(d)] = d));
+  const resolve = reference =>
+    byKey[reference] ||
+    declarations.find(d => d.goName === reference.split(".")[1]);
+
+  const kept = new Set(
+    declarations
+      .filter(
+        d =>
+          /^[A-Z]/.test(d.goName) ||
+          d.kind === "client" ||
+          roots.includes(key(d))
+      )
+      .map(key)
+  );
+
+  // Types referenced by the emitted ones
+  if (policy === "referenced") {
+    const queue = Array.from(kept);
+    while (queue.length) {
+      const d = byKey[queue.pop()];
+      (d.fields || [])
+        .map(f => f.type)
+        .concat(d.parent || [], d.type || [])
+        .map(goReferences)
+        .reduce((mem, references) => mem.concat(references), [])
+        .map(resolve)
+        .filter(i => i && !kept.has(key(i)))
+        .forEach(i => {
+          kept.add(key(i));
+          queue.push(key(i));
+        });
+    }
+  }
+
+  const isSkipped = reference => {
+    const d = resolve(reference);
+    return Boolean(d) && !kept.has(key(d));
+  };
+  const replaceSkipped = type =>
+    type.replace(/"[^"]*"|'[^']*'|\b\w+\.\w+\b/g, match =>
+      isSkipped(match) ? "unknown" : match
+    );
+
+  return declarations
+    .filter(d => kept.has(key(d)))
+    .map(declaration => {
+      const result = Object.assign({}, declaration);
+      if (declaration.type) result.type = replaceSkipped(declaration.type);
+      if (declaration.parent && isSkipped(declaration.parent)) {
+        result.parent = undefined;
+      }
+      if (declaration.fields) {
+        result.fields = declaration.fields.map(field =>
+          Object.assign({}, field, { type: replaceSkipped(field.type) })
+        );
+      }
+      return result;
+    });
+};
+
+module.exports = applyUnexported;